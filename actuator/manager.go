@@ -58,7 +58,8 @@ type GlobalState struct {
 	Components map[string]State `json:"components"`
 }
 
-// Health returns a GlobalState aggregate. Will set global Status as StatusDown if any of registered components is down.
+// Health returns a GlobalState aggregate. Global Status is the most severe Status of registered components (e.g.
+// StatusDown if any of them is down).
 //
 // Moreover, uses ConfigManager.MaxGoroutines value to limit the number of concurrent requests to registered Actuator
 // instances as they might be remote calls to external components.
@@ -97,7 +98,7 @@ func (m *Manager) Health(ctx context.Context) (*GlobalState, error) {
 	}
 
 	for _, componentState := range globalState.Components {
-		if componentState.Status > globalState.Status {
+		if componentState.Status.IsWorseThan(globalState.Status) {
 			globalState.Status = componentState.Status
 		}
 	}
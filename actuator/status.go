@@ -20,12 +20,23 @@ const (
 	StatusUp
 	// StatusDown component is unhealthy and unavailable.
 	StatusDown
+	// StatusDegraded component is available with reduced performance or capacity.
+	StatusDegraded
 )
 
 var statusTextMap = map[Status]string{
-	StatusUnknown: "UNKNOWN",
-	StatusUp:      "UP",
-	StatusDown:    "DOWN",
+	StatusUnknown:  "UNKNOWN",
+	StatusUp:       "UP",
+	StatusDown:     "DOWN",
+	StatusDegraded: "DEGRADED",
+}
+
+// statusSeverityMap severity of each Status, used to aggregate component states.
+var statusSeverityMap = map[Status]int{
+	StatusUnknown:  0,
+	StatusUp:       1,
+	StatusDegraded: 2,
+	StatusDown:     3,
 }
 
 func (s Status) String() string {
@@ -35,3 +46,14 @@ func (s Status) String() string {
 func (s Status) MarshalText() (text []byte, err error) {
 	return []byte(s.String()), nil
 }
+
+// IsAvailable indicates whether the component is able to serve requests (StatusUp or StatusDegraded).
+func (s Status) IsAvailable() bool {
+	return s == StatusUp || s == StatusDegraded
+}
+
+// IsWorseThan indicates whether s is more severe than other (StatusDown > StatusDegraded > StatusUp >
+// StatusUnknown).
+func (s Status) IsWorseThan(other Status) bool {
+	return statusSeverityMap[s] > statusSeverityMap[other]
+}
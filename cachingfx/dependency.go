@@ -0,0 +1,202 @@
+package cachingfx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/caarlos0/env/v11"
+	"go.uber.org/fx"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/actuatorfx"
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/data/caching/lock"
+)
+
+// Module provides caching.Cache using the caching.Backend selected by caching.BackendConfig, and registers
+// the actuators of the selected backend:
+//
+//   - caching.BackendEmbedded: caching.CacheEmbedded driven by caching.BigCacheConfig.
+//   - caching.BackendRedis: caching.CacheRedis driven by caching.RedisConfig.
+//   - caching.BackendNear: caching.CacheNear driven by caching.NearCacheConfig, combining both.
+//
+// Use EmbeddedModule, RedisModule or NearModule instead to get backend specific dependencies (e.g.
+// *bigcache.BigCache).
+var Module = fx.Module("caching",
+	fx.Provide(
+		env.ParseAs[caching.BackendConfig],
+		env.ParseAs[caching.BigCacheConfig],
+		env.ParseAs[caching.EmbeddedActuatorConfig],
+		env.ParseAs[caching.RedisConfig],
+		env.ParseAs[caching.NearCacheConfig],
+		fx.Private,
+	),
+	fx.Provide(
+		newBackend,
+	),
+)
+
+type backendParams struct {
+	fx.In
+
+	Lifecycle       fx.Lifecycle
+	Config          caching.BackendConfig
+	BigCacheConfig  caching.BigCacheConfig
+	ActuatorConfig  caching.EmbeddedActuatorConfig
+	RedisConfig     caching.RedisConfig
+	NearCacheConfig caching.NearCacheConfig
+}
+
+type backendResult struct {
+	fx.Out
+
+	Cache     caching.Cache
+	Actuators []actuator.Actuator `group:"actuators,flatten"`
+}
+
+// newBackend allocates the caching.Cache of the selected caching.Backend, so dependencies of other backends
+// (e.g. Redis connections) are never allocated.
+func newBackend(params backendParams) (backendResult, error) {
+	switch params.Config.Backend {
+	case caching.BackendEmbedded:
+		cache, act, err := newEmbeddedBackend(params)
+		return backendResult{Cache: cache, Actuators: []actuator.Actuator{act}}, err
+	case caching.BackendRedis:
+		client := caching.NewRedisClient(params.Lifecycle, params.RedisConfig)
+		return backendResult{
+			Cache:     caching.NewCacheRedis(client, params.RedisConfig),
+			Actuators: []actuator.Actuator{caching.NewRedisActuator(client)},
+		}, nil
+	case caching.BackendNear:
+		l1, act, err := newEmbeddedBackend(params)
+		if err != nil {
+			return backendResult{}, err
+		}
+		client := caching.NewRedisClient(params.Lifecycle, params.RedisConfig)
+		bus := caching.NewInvalidationBusRedis(client, params.NearCacheConfig)
+		cache, err := newCacheNear(params.Lifecycle, params.NearCacheConfig, l1,
+			caching.NewCacheRedis(client, params.RedisConfig), bus)
+		if err != nil {
+			return backendResult{}, err
+		}
+		return backendResult{
+			Cache: cache,
+			Actuators: []actuator.Actuator{act, caching.NewRedisActuator(client),
+				caching.NewNearActuator(cache)},
+		}, nil
+	default:
+		return backendResult{}, fmt.Errorf("%w: %s", caching.ErrUnsupportedBackend, params.Config.Backend)
+	}
+}
+
+func newEmbeddedBackend(params backendParams) (caching.CacheEmbedded, *caching.EmbeddedActuator, error) {
+	evictions := caching.NewBigCacheEvictions()
+	db, err := caching.NewBigCache(params.Lifecycle, params.BigCacheConfig, evictions)
+	if err != nil {
+		return caching.CacheEmbedded{}, nil, err
+	}
+	return newCacheEmbedded(db, params.BigCacheConfig),
+		caching.NewEmbeddedActuator(db, evictions, params.ActuatorConfig), nil
+}
+
+// EmbeddedModule provides caching.Cache backed by caching.CacheEmbedded (process-local), and registers
+// caching.EmbeddedActuator.
+var EmbeddedModule = fx.Module("caching_embedded",
+	fx.Provide(
+		env.ParseAs[caching.BigCacheConfig],
+		env.ParseAs[caching.EmbeddedActuatorConfig],
+		caching.NewBigCacheEvictions,
+		caching.NewBigCache,
+		fx.Annotate(
+			newCacheEmbedded,
+			fx.As(new(caching.Cache)),
+		),
+		actuatorfx.AsActuator(caching.NewEmbeddedActuator),
+	),
+)
+
+// RedisModule provides caching.Cache backed by caching.CacheRedis, driven by caching.RedisConfig, and
+// registers caching.RedisActuator.
+var RedisModule = fx.Module("caching_redis",
+	fx.Provide(
+		env.ParseAs[caching.RedisConfig],
+		caching.NewRedisClient,
+		fx.Annotate(
+			caching.NewCacheRedis,
+			fx.As(new(caching.Cache)),
+		),
+		actuatorfx.AsActuator(caching.NewRedisActuator),
+	),
+)
+
+// LockModule provides lock.Locker storing leases into the caching.Cache provided by another module (e.g.
+// RedisModule).
+var LockModule = fx.Module("caching_lock",
+	fx.Provide(
+		env.ParseAs[lock.Config],
+		lock.NewLocker,
+	),
+)
+
+// NearModule provides caching.Cache backed by caching.CacheNear, using caching.CacheEmbedded as local tier
+// and caching.CacheRedis as remote tier, broadcasting invalidations with caching.InvalidationBusRedis.
+// Registers caching.EmbeddedActuator, caching.RedisActuator and caching.NearActuator.
+var NearModule = fx.Module("caching_near",
+	fx.Provide(
+		env.ParseAs[caching.BigCacheConfig],
+		env.ParseAs[caching.EmbeddedActuatorConfig],
+		env.ParseAs[caching.RedisConfig],
+		env.ParseAs[caching.NearCacheConfig],
+		caching.NewBigCacheEvictions,
+		caching.NewBigCache,
+		caching.NewRedisClient,
+		fx.Private,
+	),
+	fx.Provide(
+		fx.Annotate(
+			caching.NewInvalidationBusRedis,
+			fx.As(new(caching.InvalidationBus)),
+		),
+		fx.Annotate(
+			newNearModuleCache,
+			fx.As(new(caching.Cache)),
+			fx.As(fx.Self()),
+		),
+		actuatorfx.AsActuator(caching.NewEmbeddedActuator),
+		actuatorfx.AsActuator(caching.NewRedisActuator),
+		actuatorfx.AsActuator(caching.NewNearActuator),
+	),
+)
+
+func newCacheNear(lifecycle fx.Lifecycle, cfg caching.NearCacheConfig, l1, l2 caching.Cache,
+	bus caching.InvalidationBus) (*caching.CacheNear, error) {
+	cache, err := caching.NewCacheNear(cfg, l1, l2, bus)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return cache.Start(ctx)
+		},
+		OnStop: func(_ context.Context) error {
+			return cache.Close()
+		},
+	})
+	return cache, nil
+}
+
+func newNearModuleCache(lifecycle fx.Lifecycle, cfg caching.NearCacheConfig, db *bigcache.BigCache,
+	bigCacheCfg caching.BigCacheConfig, client redis.UniversalClient, redisCfg caching.RedisConfig,
+	bus caching.InvalidationBus) (*caching.CacheNear, error) {
+	return newCacheNear(lifecycle, cfg, newCacheEmbedded(db, bigCacheCfg), caching.NewCacheRedis(client, redisCfg),
+		bus)
+}
+
+func newCacheEmbedded(db *bigcache.BigCache, cfg caching.BigCacheConfig) caching.CacheEmbedded {
+	cache := caching.NewCacheEmbedded(db)
+	cache.ItemTTL = cfg.ItemTTL
+	return cache
+}
@@ -0,0 +1,136 @@
+package caching
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+
+	"github.com/neutrinocorp/geck/actuator"
+)
+
+// embeddedActuatorSample counters of a bigcache.BigCache at a point in time.
+type embeddedActuatorSample struct {
+	at      time.Time
+	hits    int64
+	misses  int64
+	noSpace uint64
+}
+
+// sub returns the counters accumulated since previous.
+func (s embeddedActuatorSample) sub(previous embeddedActuatorSample) embeddedActuatorSample {
+	return embeddedActuatorSample{
+		at:      previous.at,
+		hits:    s.hits - previous.hits,
+		misses:  s.misses - previous.misses,
+		noSpace: s.noSpace - previous.noSpace,
+	}
+}
+
+// EmbeddedActuator is the actuator.Actuator implementation for CacheEmbedded, reporting bigcache.BigCache
+// statistics. The reported capacity is the memory allocated by the cache, in bytes, which is never released
+// as entries are removed.
+//
+// The cache is considered actuator.StatusDegraded if, over the last EmbeddedActuatorConfig.Window, its hit
+// ratio falls below EmbeddedActuatorConfig.MinHitRatio or entries are evicted for lack of space (i.e. no
+// free capacity left) faster than EmbeddedActuatorConfig.MaxNoSpaceEvictionRate. The current window is
+// evaluated until the first one completes.
+type EmbeddedActuator struct {
+	DB        *bigcache.BigCache
+	Evictions *BigCacheEvictions
+	Config    EmbeddedActuatorConfig
+
+	mu          sync.Mutex
+	windowStart embeddedActuatorSample
+	lastWindow  *embeddedActuatorSample
+	lastEnd     time.Time
+}
+
+var _ actuator.Actuator = (*EmbeddedActuator)(nil)
+
+func NewEmbeddedActuator(db *bigcache.BigCache, evictions *BigCacheEvictions,
+	cfg EmbeddedActuatorConfig) *EmbeddedActuator {
+	return &EmbeddedActuator{
+		DB:          db,
+		Evictions:   evictions,
+		Config:      cfg,
+		windowStart: embeddedActuatorSample{at: time.Now()},
+	}
+}
+
+func (a *EmbeddedActuator) sample(stats bigcache.Stats) embeddedActuatorSample {
+	sample := embeddedActuatorSample{
+		at:     time.Now(),
+		hits:   stats.Hits,
+		misses: stats.Misses,
+	}
+	if a.Evictions != nil {
+		sample.noSpace = a.Evictions.NoSpace()
+	}
+	return sample
+}
+
+// getWindow returns the counters of the window to evaluate along with its duration, rotating windows
+// every Config.Window.
+func (a *EmbeddedActuator) getWindow(current embeddedActuatorSample) (embeddedActuatorSample, time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if current.at.Sub(a.windowStart.at) >= a.Config.Window {
+		window := current.sub(a.windowStart)
+		a.lastWindow, a.lastEnd = &window, current.at
+		a.windowStart = current
+	}
+	if a.lastWindow != nil {
+		return *a.lastWindow, a.lastEnd.Sub(a.lastWindow.at)
+	}
+	return current.sub(a.windowStart), current.at.Sub(a.windowStart.at)
+}
+
+func (a *EmbeddedActuator) State(_ context.Context) (actuator.State, error) {
+	stats := a.DB.Stats()
+	details := map[string]any{
+		"entries":    a.DB.Len(),
+		"capacity":   a.DB.Capacity(),
+		"hits":       stats.Hits,
+		"misses":     stats.Misses,
+		"del_hits":   stats.DelHits,
+		"del_misses": stats.DelMisses,
+		"collisions": stats.Collisions,
+	}
+	if a.Evictions != nil {
+		details["evictions_expired"] = a.Evictions.Expired()
+		details["evictions_no_space"] = a.Evictions.NoSpace()
+	}
+
+	window, duration := a.getWindow(a.sample(stats))
+	degradedReasons := make([]string, 0, 2)
+	if lookups := window.hits + window.misses; lookups > 0 {
+		hitRatio := float64(window.hits) / float64(lookups)
+		details["hit_ratio"] = hitRatio
+		if lookups >= a.Config.MinLookups && hitRatio < a.Config.MinHitRatio {
+			degradedReasons = append(degradedReasons, fmt.Sprintf("hit ratio %.2f is below %.2f", hitRatio,
+				a.Config.MinHitRatio))
+		}
+	}
+	if a.Evictions != nil && duration > 0 {
+		evictionRate := float64(window.noSpace) / duration.Seconds()
+		details["no_space_eviction_rate"] = evictionRate
+		if a.Config.MaxNoSpaceEvictionRate > 0 && evictionRate > a.Config.MaxNoSpaceEvictionRate {
+			degradedReasons = append(degradedReasons, fmt.Sprintf("no space eviction rate %.2f/s is above %.2f/s",
+				evictionRate, a.Config.MaxNoSpaceEvictionRate))
+		}
+	}
+
+	status := actuator.StatusUp
+	if len(degradedReasons) > 0 {
+		status = actuator.StatusDegraded
+	}
+	return actuator.State{
+		Status:      status,
+		Description: strings.Join(degradedReasons, "; "),
+		Details:     details,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package caching_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+func TestEmbeddedActuator(t *testing.T) {
+	ctx := context.Background()
+	evictions := caching.NewBigCacheEvictions()
+	cfg := bigcache.DefaultConfig(time.Minute)
+	cfg.OnRemoveWithReason = evictions.OnRemove
+	db, err := bigcache.New(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	cache := caching.NewCacheEmbedded(db)
+	act := caching.NewEmbeddedActuator(db, evictions, caching.EmbeddedActuatorConfig{
+		Window:                 100 * time.Millisecond,
+		MinHitRatio:            0.5,
+		MinLookups:             4,
+		MaxNoSpaceEvictionRate: 1,
+	})
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1")))
+	_, _ = cache.Get(ctx, "a")
+	_, _ = cache.Get(ctx, "missing")
+	_, _ = cache.Get(ctx, "missing")
+	state, err := act.State(ctx)
+	require.NoError(t, err)
+	// cold caches are not evaluated
+	assert.Equal(t, actuator.StatusUp, state.Status)
+	details := state.Details.(map[string]any)
+	assert.Equal(t, 1, details["entries"])
+	assert.Equal(t, db.Capacity(), details["capacity"])
+	assert.Equal(t, int64(1), details["hits"])
+	assert.Equal(t, int64(2), details["misses"])
+	assert.Equal(t, uint64(0), details["evictions_no_space"])
+
+	_, _ = cache.Get(ctx, "missing")
+	state, err = act.State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusDegraded, state.Status)
+	assert.Equal(t, "hit ratio 0.25 is below 0.50", state.Description)
+
+	// completed windows are evaluated, so the cache recovers from early misses
+	time.Sleep(100 * time.Millisecond)
+	state, err = act.State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusDegraded, state.Status)
+	for i := 0; i < 4; i++ {
+		_, _ = cache.Get(ctx, "a")
+	}
+	time.Sleep(100 * time.Millisecond)
+	state, err = act.State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusUp, state.Status)
+	assert.Equal(t, 1.0, state.Details.(map[string]any)["hit_ratio"])
+
+	// entries evicted for lack of space degrade the cache
+	for i := 0; i < 5; i++ {
+		evictions.OnRemove("a", nil, bigcache.NoSpace)
+	}
+	time.Sleep(100 * time.Millisecond)
+	state, err = act.State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusDegraded, state.Status)
+	assert.Contains(t, state.Description, "no space eviction rate")
+	time.Sleep(100 * time.Millisecond)
+	state, err = act.State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusUp, state.Status)
+
+	evictions.OnRemove("b", nil, bigcache.Expired)
+	evictions.OnRemove("c", nil, bigcache.Deleted)
+	assert.Equal(t, uint64(5), evictions.NoSpace())
+	assert.Equal(t, uint64(1), evictions.Expired())
+}
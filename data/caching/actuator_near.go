@@ -0,0 +1,35 @@
+package caching
+
+import (
+	"context"
+
+	"github.com/neutrinocorp/geck/actuator"
+)
+
+// NearActuator is the actuator.Actuator implementation for CacheNear, reporting its hit statistics
+// (NearCacheStats). Availability of each tier is reported by their own actuator (e.g. RedisActuator).
+type NearActuator struct {
+	Cache *CacheNear
+}
+
+var _ actuator.Actuator = (*NearActuator)(nil)
+
+func NewNearActuator(cache *CacheNear) NearActuator {
+	return NearActuator{
+		Cache: cache,
+	}
+}
+
+func (a NearActuator) State(_ context.Context) (actuator.State, error) {
+	stats := a.Cache.Stats()
+	return actuator.State{
+		Status: actuator.StatusUp,
+		Details: map[string]any{
+			"l1_hits":      stats.L1Hits,
+			"l2_hits":      stats.L2Hits,
+			"misses":       stats.Misses,
+			"l1_hit_ratio": stats.L1HitRatio,
+			"l2_hit_ratio": stats.L2HitRatio,
+		},
+	}, nil
+}
@@ -0,0 +1,54 @@
+package caching
+
+import (
+	"bufio"
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/neutrinocorp/geck/actuator"
+)
+
+type RedisActuator struct {
+	Client redis.UniversalClient
+}
+
+var _ actuator.Actuator = (*RedisActuator)(nil)
+
+func NewRedisActuator(client redis.UniversalClient) RedisActuator {
+	return RedisActuator{
+		Client: client,
+	}
+}
+
+func (a RedisActuator) State(ctx context.Context) (actuator.State, error) {
+	if err := a.Client.Ping(ctx).Err(); err != nil {
+		return actuator.State{
+			Status:      actuator.StatusDown,
+			Description: err.Error(),
+		}, nil
+	}
+
+	details := map[string]any{}
+	if info, err := a.Client.Info(ctx, "server").Result(); err == nil {
+		scanner := bufio.NewScanner(strings.NewReader(info))
+		for scanner.Scan() {
+			if version, ok := strings.CutPrefix(scanner.Text(), "redis_version:"); ok {
+				details["version"] = strings.TrimSpace(version)
+				break
+			}
+		}
+	}
+	if stats := a.Client.PoolStats(); stats != nil {
+		details["pool_hits"] = stats.Hits
+		details["pool_misses"] = stats.Misses
+		details["pool_timeouts"] = stats.Timeouts
+		details["pool_total_conns"] = stats.TotalConns
+		details["pool_idle_conns"] = stats.IdleConns
+	}
+	return actuator.State{
+		Status:  actuator.StatusUp,
+		Details: details,
+	}, nil
+}
@@ -2,13 +2,64 @@ package caching
 
 import (
 	"context"
+	"math"
+	"sync/atomic"
+	"time"
 
 	"github.com/allegro/bigcache/v3"
 	"go.uber.org/fx"
 )
 
-func NewBigCache(lifecycle fx.Lifecycle, cfg BigCacheConfig) (*bigcache.BigCache, error) {
-	bc, err := bigcache.New(context.Background(), bigcache.DefaultConfig(cfg.ItemTTL))
+// bigCacheLifeWindow disables bigcache.Config.LifeWindow, so bigcache.BigCache never evicts entries by time.
+const bigCacheLifeWindow = time.Duration(math.MaxInt64)
+
+// BigCacheEvictions counts entries removed by bigcache.BigCache instances, by reason.
+type BigCacheEvictions struct {
+	expired atomic.Uint64
+	noSpace atomic.Uint64
+}
+
+func NewBigCacheEvictions() *BigCacheEvictions {
+	return &BigCacheEvictions{}
+}
+
+// OnRemove implements bigcache.Config.OnRemoveWithReason.
+func (e *BigCacheEvictions) OnRemove(_ string, _ []byte, reason bigcache.RemoveReason) {
+	switch reason {
+	case bigcache.Expired:
+		e.expired.Add(1)
+	case bigcache.NoSpace:
+		e.noSpace.Add(1)
+	}
+}
+
+// Expired retrieves the number of entries removed after expiring.
+func (e *BigCacheEvictions) Expired() uint64 {
+	return e.expired.Load()
+}
+
+// NoSpace retrieves the number of entries removed to free space for new ones.
+func (e *BigCacheEvictions) NoSpace() uint64 {
+	return e.noSpace.Load()
+}
+
+// NewBigCache allocates a bigcache.BigCache instance driven by cfg, closed on application stop. Removed
+// entries are counted by evictions.
+//
+// Time-based eviction (bigcache.Config.LifeWindow) is disabled as it ignores per-key expiration. Entries expire
+// according to CacheEmbedded expiration headers instead (cfg.ItemTTL by default, see CacheEmbedded.ItemTTL),
+// and are only evicted to free space once cfg.HardMaxCacheSize is reached.
+func NewBigCache(lifecycle fx.Lifecycle, cfg BigCacheConfig, evictions *BigCacheEvictions) (*bigcache.BigCache,
+	error) {
+	bcConfig := bigcache.DefaultConfig(bigCacheLifeWindow)
+	bcConfig.Shards = cfg.Shards
+	bcConfig.CleanWindow = cfg.CleanWindow
+	bcConfig.MaxEntriesInWindow = cfg.MaxEntriesInWindow
+	bcConfig.MaxEntrySize = cfg.MaxEntrySize
+	bcConfig.HardMaxCacheSize = cfg.HardMaxCacheSize
+	bcConfig.Verbose = cfg.Verbose
+	bcConfig.OnRemoveWithReason = evictions.OnRemove
+	bc, err := bigcache.New(context.Background(), bcConfig)
 	if err != nil {
 		return nil, err
 	}
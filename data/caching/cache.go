@@ -2,15 +2,50 @@ package caching
 
 import (
 	"context"
+	"time"
 )
 
+// NoExpiration TTL of entries without per-key expiration. Entries might still be evicted by the backend to
+// free space (e.g. BigCacheConfig.HardMaxCacheSize).
+const NoExpiration time.Duration = -1
+
+// Cache a key-value store for transient data.
+//
+// Read operations return ErrCacheMiss if the key was not found or has expired.
 type Cache interface {
+	// Set stores value using the backend default expiration.
 	Set(ctx context.Context, key string, value []byte) error
+	// SetWithTTL stores value, expiring after ttl. Zero ttl stores value without expiration.
+	SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error
 	SetMany(ctx context.Context, keyValues map[string][]byte) error
+	// SetIfNotExists atomically stores value, expiring after ttl, only if key is missing. Zero ttl stores
+	// value without expiration. Returns true if value was stored.
+	SetIfNotExists(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// CompareAndSwap atomically replaces the value of key with newValue only if it is equal to oldValue,
+	// keeping its expiration. Returns true if value was replaced.
+	CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte) (bool, error)
+	// Increment atomically adds delta to the counter stored in key, returning its new value. Counters are
+	// stored as base-10 integers and missing keys start at zero. Returns ErrNotInteger if the value of key
+	// is not a counter.
+	Increment(ctx context.Context, key string, delta int64) (int64, error)
 	Append(ctx context.Context, key string, value []byte) error
+	// Add appends value as an item of the list stored in key, allocating the list if missing.
 	Add(ctx context.Context, key string, value []byte) error
+	// List retrieves the items of the list stored in key, in insertion order.
 	List(ctx context.Context, key string) ([][]byte, error)
+	// Remove removes every item equal to value from the list stored in key. Returns ErrCacheMiss if no item
+	// was removed. Lists left empty are removed.
+	Remove(ctx context.Context, key string, value []byte) error
+	// Len retrieves the number of items of the list stored in key.
+	Len(ctx context.Context, key string) (int64, error)
+	// Trim bounds the list stored in key to its last maxLen items, removing the oldest ones. A maxLen lower
+	// than one removes the list.
+	Trim(ctx context.Context, key string, maxLen int64) error
 	Get(ctx context.Context, key string) ([]byte, error)
+	// Touch resets the expiration of key to ttl. Zero ttl removes its expiration.
+	Touch(ctx context.Context, key string, ttl time.Duration) error
+	// TTL retrieves the remaining time to live of key. Returns NoExpiration if key does not expire.
+	TTL(ctx context.Context, key string) (time.Duration, error)
 	Delete(ctx context.Context, key string) error
 	DeleteMany(ctx context.Context, keys []string) error
 }
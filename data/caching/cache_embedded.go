@@ -1,20 +1,42 @@
 package caching
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/allegro/bigcache/v3"
 )
 
 const (
-	embeddedAppendValueSeparator byte = '\n'
+	// embeddedHeaderSize size of the expiry header prepended to every stored value: expiration time as
+	// big-endian Unix nanoseconds, zero if entry does not expire.
+	embeddedHeaderSize  = 8
+	embeddedLockStripes = 256
 )
 
+// embeddedLocks striped locks serializing read-modify-write operations of every CacheEmbedded instance.
+// Shared at package level, so CacheEmbedded zero values (e.g. CacheEmbedded{DB: db}) remain usable.
+var embeddedLocks [embeddedLockStripes]sync.Mutex
+
+// CacheEmbedded is the bigcache implementation of Cache.
+//
+// Per-key expiration is stored in a header prepended to each value, expired entries are removed lazily
+// when read. Read-modify-write operations are serialized using striped locks.
+//
+// DB must not evict entries by time (i.e. allocated with NewBigCache), as bigcache.Config.LifeWindow applies
+// to every entry regardless of its expiration.
 type CacheEmbedded struct {
 	DB *bigcache.BigCache
+	// ItemTTL expiration of entries written by Set and SetMany, or allocated by Increment, Append and Add.
+	// Entries never expire if zero.
+	ItemTTL time.Duration
 }
 
 var _ Cache = (*CacheEmbedded)(nil)
@@ -25,8 +47,55 @@ func NewCacheEmbedded(db *bigcache.BigCache) CacheEmbedded {
 	}
 }
 
-func (m CacheEmbedded) Set(_ context.Context, key string, value []byte) error {
-	return m.DB.Set(key, value)
+func (m CacheEmbedded) getLock(key string) *sync.Mutex {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	return &embeddedLocks[hasher.Sum32()%embeddedLockStripes]
+}
+
+func newEmbeddedEntry(value []byte, ttl time.Duration) []byte {
+	entry := make([]byte, embeddedHeaderSize, embeddedHeaderSize+len(value))
+	if ttl > 0 {
+		binary.BigEndian.PutUint64(entry, uint64(time.Now().Add(ttl).UnixNano()))
+	}
+	return append(entry, value...)
+}
+
+func readEmbeddedExpiration(entry []byte) time.Time {
+	expiresAt := binary.BigEndian.Uint64(entry[:embeddedHeaderSize])
+	if expiresAt == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(expiresAt))
+}
+
+// getEntry retrieves the stored entry (header included) of key, removing it if expired.
+func (m CacheEmbedded) getEntry(key string) ([]byte, error) {
+	entry, err := m.DB.Get(key)
+	if errors.Is(err, bigcache.ErrEntryNotFound) {
+		return nil, ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	} else if len(entry) < embeddedHeaderSize {
+		return nil, ErrCacheMiss
+	}
+
+	if expiresAt := readEmbeddedExpiration(entry); !expiresAt.IsZero() && !time.Now().Before(expiresAt) {
+		_ = m.DB.Delete(key)
+		return nil, ErrCacheMiss
+	}
+	return entry, nil
+}
+
+func (m CacheEmbedded) Set(ctx context.Context, key string, value []byte) error {
+	return m.SetWithTTL(ctx, key, value, m.ItemTTL)
+}
+
+func (m CacheEmbedded) SetWithTTL(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	return m.DB.Set(key, newEmbeddedEntry(value, ttl))
 }
 
 func (m CacheEmbedded) SetMany(_ context.Context, keyValues map[string][]byte) (err error) {
@@ -42,7 +111,11 @@ func (m CacheEmbedded) SetMany(_ context.Context, keyValues map[string][]byte) (
 		}
 	}()
 	for k, v := range keyValues {
-		if err = m.DB.Set(k, v); err != nil {
+		mu := m.getLock(k)
+		mu.Lock()
+		err = m.DB.Set(k, newEmbeddedEntry(v, m.ItemTTL))
+		mu.Unlock()
+		if err != nil {
 			return
 		}
 		successKeys = append(successKeys, k)
@@ -50,48 +123,123 @@ func (m CacheEmbedded) SetMany(_ context.Context, keyValues map[string][]byte) (
 	return nil
 }
 
-func (m CacheEmbedded) Append(_ context.Context, key string, value []byte) error {
+func (m CacheEmbedded) SetIfNotExists(_ context.Context, key string, value []byte,
+	ttl time.Duration) (bool, error) {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := m.getEntry(key); err == nil {
+		return false, nil
+	} else if !errors.Is(err, ErrCacheMiss) {
+		return false, err
+	}
+	return true, m.DB.Set(key, newEmbeddedEntry(value, ttl))
+}
+
+func (m CacheEmbedded) CompareAndSwap(_ context.Context, key string, oldValue, newValue []byte) (bool, error) {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	entry, err := m.getEntry(key)
+	if err != nil {
+		return false, err
+	} else if !bytes.Equal(entry[embeddedHeaderSize:], oldValue) {
+		return false, nil
+	}
+	return true, m.setValue(key, entry[:embeddedHeaderSize], newValue)
+}
+
+func (m CacheEmbedded) Increment(_ context.Context, key string, delta int64) (int64, error) {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	entry, err := m.getEntry(key)
+	if errors.Is(err, ErrCacheMiss) {
+		entry = newEmbeddedEntry([]byte("0"), m.ItemTTL)
+	} else if err != nil {
+		return 0, err
+	}
+
+	counter, err := strconv.ParseInt(string(entry[embeddedHeaderSize:]), 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+	if (delta > 0 && counter > math.MaxInt64-delta) || (delta < 0 && counter < math.MinInt64-delta) {
+		return 0, ErrNotInteger
+	}
+	counter += delta
+	return counter, m.setValue(key, entry[:embeddedHeaderSize], strconv.AppendInt(nil, counter, 10))
+}
+
+// setValue stores value as the entry of key using header, keeping its expiration.
+func (m CacheEmbedded) setValue(key string, header, value []byte) error {
+	entry := make([]byte, 0, embeddedHeaderSize+len(value))
+	entry = append(entry, header...)
+	return m.DB.Set(key, append(entry, value...))
+}
+
+// appendEntry appends value to the entry of key, keeping its expiration. Allocates a new entry expiring after
+// ItemTTL if key is missing.
+func (m CacheEmbedded) appendEntry(key string, value []byte) error {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	if _, err := m.getEntry(key); errors.Is(err, ErrCacheMiss) {
+		return m.DB.Set(key, newEmbeddedEntry(value, m.ItemTTL))
+	} else if err != nil {
+		return err
+	}
 	return m.DB.Append(key, value)
 }
 
-func (m CacheEmbedded) Add(_ context.Context, key string, value []byte) error {
-	formattedVal := make([]byte, 0, 1+len(value))
-	formattedVal = append(formattedVal, embeddedAppendValueSeparator)
-	formattedVal = append(formattedVal, value...)
-	return m.DB.Append(key, formattedVal)
+func (m CacheEmbedded) Append(_ context.Context, key string, value []byte) error {
+	return m.appendEntry(key, value)
 }
 
-func (m CacheEmbedded) List(_ context.Context, key string) ([][]byte, error) {
-	listBytes, err := m.DB.Get(key)
+func (m CacheEmbedded) Get(_ context.Context, key string) ([]byte, error) {
+	entry, err := m.getEntry(key)
 	if err != nil {
 		return nil, err
-	} else if len(listBytes) <= 1 {
-		return nil, nil
 	}
+	return entry[embeddedHeaderSize:], nil
+}
 
-	// remove first item separator
-	listBytes = listBytes[1:]
-	out := make([][]byte, 0)
-	scanner := bufio.NewScanner(bytes.NewReader(listBytes))
-	for scanner.Scan() {
-		item := scanner.Bytes()
-		out = append(out, item)
+func (m CacheEmbedded) Touch(_ context.Context, key string, ttl time.Duration) error {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	entry, err := m.getEntry(key)
+	if err != nil {
+		return err
 	}
-	return out, nil
+	return m.DB.Set(key, newEmbeddedEntry(entry[embeddedHeaderSize:], ttl))
 }
 
-func (m CacheEmbedded) Get(_ context.Context, key string) ([]byte, error) {
-	return m.DB.Get(key)
+func (m CacheEmbedded) TTL(_ context.Context, key string) (time.Duration, error) {
+	entry, err := m.getEntry(key)
+	if err != nil {
+		return 0, err
+	}
+	expiresAt := readEmbeddedExpiration(entry)
+	if expiresAt.IsZero() {
+		return NoExpiration, nil
+	}
+	return time.Until(expiresAt), nil
 }
 
 func (m CacheEmbedded) Delete(_ context.Context, key string) error {
-	return m.DB.Delete(key)
+	if err := m.DB.Delete(key); errors.Is(err, bigcache.ErrEntryNotFound) {
+		return ErrCacheMiss
+	} else if err != nil {
+		return err
+	}
+	return nil
 }
 
-func (m CacheEmbedded) DeleteMany(_ context.Context, keys []string) error {
+func (m CacheEmbedded) DeleteMany(ctx context.Context, keys []string) error {
 	errs := make([]error, 0, len(keys))
 	for _, key := range keys {
-		if err := m.DB.Delete(key); err != nil {
+		if err := m.Delete(ctx, key); err != nil {
 			errs = append(errs, err)
 			continue
 		}
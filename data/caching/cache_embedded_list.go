@@ -0,0 +1,148 @@
+package caching
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// embeddedListVersion first byte of lists encoded as a sequence of items, each prefixed with its
+	// length (unsigned varint).
+	embeddedListVersion byte = 0x01
+	// embeddedLegacyListSeparator first byte of lists encoded by previous versions, where items were
+	// prefixed with a newline. Such lists are still readable and are rewritten using embeddedListVersion
+	// on their next mutation.
+	embeddedLegacyListSeparator byte = '\n'
+)
+
+func appendEmbeddedListItem(dst, item []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(len(item)))
+	return append(dst, item...)
+}
+
+func encodeEmbeddedList(items [][]byte) []byte {
+	size := 1
+	for _, item := range items {
+		size += binary.MaxVarintLen64 + len(item)
+	}
+	list := make([]byte, 1, size)
+	list[0] = embeddedListVersion
+	for _, item := range items {
+		list = appendEmbeddedListItem(list, item)
+	}
+	return list
+}
+
+// decodeEmbeddedList decodes the items of list, either using the current or the legacy encoding.
+func decodeEmbeddedList(list []byte) ([][]byte, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	switch list[0] {
+	case embeddedLegacyListSeparator:
+		return bytes.Split(list[1:], []byte{embeddedLegacyListSeparator}), nil
+	case embeddedListVersion:
+	default:
+		return nil, ErrMalformedList
+	}
+
+	items := make([][]byte, 0)
+	for list = list[1:]; len(list) > 0; {
+		size, n := binary.Uvarint(list)
+		if n <= 0 || size > uint64(len(list)-n) {
+			return nil, ErrMalformedList
+		}
+		list = list[n:]
+		items = append(items, list[:size:size])
+		list = list[size:]
+	}
+	return items, nil
+}
+
+// getList retrieves the entry header and the decoded items of the list stored in key.
+func (m CacheEmbedded) getList(key string) ([]byte, [][]byte, error) {
+	entry, err := m.getEntry(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := decodeEmbeddedList(entry[embeddedHeaderSize:])
+	return entry[:embeddedHeaderSize], items, err
+}
+
+// setList stores items as the list of key using header, keeping its expiration. Removes key if items is
+// empty.
+func (m CacheEmbedded) setList(key string, header []byte, items [][]byte) error {
+	if len(items) == 0 {
+		return m.DB.Delete(key)
+	}
+	return m.setValue(key, header, encodeEmbeddedList(items))
+}
+
+// Add appends value to the list of key. Lists using the legacy encoding are rewritten.
+func (m CacheEmbedded) Add(_ context.Context, key string, value []byte) error {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	entry, err := m.getEntry(key)
+	if errors.Is(err, ErrCacheMiss) {
+		return m.DB.Set(key, newEmbeddedEntry(encodeEmbeddedList([][]byte{value}), m.ItemTTL))
+	} else if err != nil {
+		return err
+	}
+
+	if list := entry[embeddedHeaderSize:]; len(list) > 0 && list[0] == embeddedListVersion {
+		return m.DB.Append(key, appendEmbeddedListItem(nil, value))
+	}
+	items, err := decodeEmbeddedList(entry[embeddedHeaderSize:])
+	if err != nil {
+		return err
+	}
+	return m.setList(key, entry[:embeddedHeaderSize], append(items, value))
+}
+
+func (m CacheEmbedded) List(_ context.Context, key string) ([][]byte, error) {
+	_, items, err := m.getList(key)
+	return items, err
+}
+
+func (m CacheEmbedded) Remove(_ context.Context, key string, value []byte) error {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	header, items, err := m.getList(key)
+	if err != nil {
+		return err
+	}
+
+	kept := make([][]byte, 0, len(items))
+	for _, item := range items {
+		if !bytes.Equal(item, value) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(items) {
+		return ErrCacheMiss
+	}
+	return m.setList(key, header, kept)
+}
+
+func (m CacheEmbedded) Len(_ context.Context, key string) (int64, error) {
+	_, items, err := m.getList(key)
+	return int64(len(items)), err
+}
+
+func (m CacheEmbedded) Trim(_ context.Context, key string, maxLen int64) error {
+	mu := m.getLock(key)
+	mu.Lock()
+	defer mu.Unlock()
+	header, items, err := m.getList(key)
+	if err != nil {
+		return err
+	} else if int64(len(items)) <= maxLen {
+		return nil
+	}
+	return m.setList(key, header, items[int64(len(items))-max(maxLen, 0):])
+}
@@ -6,6 +6,11 @@ import (
 	"time"
 
 	"github.com/allegro/bigcache/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/neutrinocorp/geck/data/caching"
 )
 
 func TestCacheEmbedded_Delete(t *testing.T) {
@@ -25,3 +30,67 @@ func TestCacheEmbedded_Delete(t *testing.T) {
 	out, _ := db.Get("criteria_hash")
 	t.Log(string(out))
 }
+
+func TestCacheEmbedded_LegacyList(t *testing.T) {
+	ctx := context.Background()
+	cache := newCacheEmbeddedTest(t)
+	// expiry header (no expiration) followed by newline-prefixed items
+	legacy := append(make([]byte, 8), []byte("\na\nb\nc")...)
+	require.NoError(t, cache.DB.Set("list", legacy))
+
+	got, err := cache.List(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c")}, got)
+
+	// mutations rewrite the list using the length-prefixed encoding
+	require.NoError(t, cache.Add(ctx, "list", []byte("d\ne")))
+	got, err = cache.List(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d\ne")}, got)
+
+	require.NoError(t, cache.Set(ctx, "value", []byte("plain")))
+	_, err = cache.List(ctx, "value")
+	assert.ErrorIs(t, err, caching.ErrMalformedList)
+}
+
+func TestCacheEmbedded_ZeroValue(t *testing.T) {
+	ctx := context.Background()
+	cache := caching.CacheEmbedded{DB: newCacheEmbeddedTest(t).DB}
+
+	ok, err := cache.SetIfNotExists(ctx, "counter", []byte("1"), 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err := cache.Increment(ctx, "counter", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got)
+}
+
+func TestCacheEmbedded_ItemTTL(t *testing.T) {
+	ctx := context.Background()
+	cfg := caching.BigCacheConfig{
+		ItemTTL:            100 * time.Millisecond,
+		Shards:             16,
+		CleanWindow:        10 * time.Millisecond,
+		MaxEntriesInWindow: 100,
+		MaxEntrySize:       64,
+	}
+	db, err := caching.NewBigCache(fxtest.NewLifecycle(t), cfg, caching.NewBigCacheEvictions())
+	require.NoError(t, err)
+	cache := caching.NewCacheEmbedded(db)
+	cache.ItemTTL = cfg.ItemTTL
+
+	require.NoError(t, cache.Set(ctx, "default", []byte("a")))
+	require.NoError(t, cache.SetWithTTL(ctx, "persistent", []byte("b"), 0))
+	require.NoError(t, cache.SetWithTTL(ctx, "hour", []byte("c"), time.Hour))
+	// bigcache evicts by seconds, so wait for its clock to move past ItemTTL
+	time.Sleep(1100 * time.Millisecond)
+
+	_, err = cache.Get(ctx, "default")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	got, err := cache.Get(ctx, "persistent")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("b"), got)
+	got, err = cache.Get(ctx, "hour")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("c"), got)
+}
@@ -0,0 +1,255 @@
+package caching
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// nearEvictionStripes number of eviction sequences of a CacheNear, keys sharing a sequence might skip L1
+// population needlessly.
+const nearEvictionStripes = 256
+
+// NearCacheStats hit statistics of a CacheNear.
+type NearCacheStats struct {
+	L1Hits uint64 `json:"l1_hits"`
+	L2Hits uint64 `json:"l2_hits"`
+	Misses uint64 `json:"misses"`
+	// L1HitRatio ratio of reads served by the local tier.
+	L1HitRatio float64 `json:"l1_hit_ratio"`
+	// L2HitRatio ratio of reads served by the remote tier.
+	L2HitRatio float64 `json:"l2_hit_ratio"`
+}
+
+// CacheNear is a two-tier Cache composed of a local (L1) and a remote (L2) Cache, e.g. CacheEmbedded and
+// CacheRedis.
+//
+// Reads are served by L1 first, falling back to L2 and populating L1 on L2 hits. Writes are applied to L2,
+// then the key is evicted from L1 and an Invalidation is broadcast through InvalidationBus, so other
+// instances evict their own L1 entry. L1 entries expire after NearCacheConfig.L1TTL, bounding staleness if
+// an Invalidation is lost. Lists (List, Len) are always read from L2. L1 is not populated with values read
+// from L2 before an eviction of their key (local write or Invalidation), so evicted values are never
+// restored into L1.
+type CacheNear struct {
+	L1     Cache
+	L2     Cache
+	Bus    InvalidationBus
+	Config NearCacheConfig
+
+	instanceID   string
+	subscription io.Closer
+	// evictions sequences incremented before evicting keys from L1, striped by key hash
+	evictions [nearEvictionStripes]atomic.Uint64
+	l1Hits    atomic.Uint64
+	l2Hits    atomic.Uint64
+	misses    atomic.Uint64
+}
+
+var _ Cache = (*CacheNear)(nil)
+
+// NewCacheNear allocates a CacheNear instance. Returns ErrInvalidL1TTL if NearCacheConfig.L1TTL is not
+// greater than zero.
+func NewCacheNear(cfg NearCacheConfig, l1, l2 Cache, bus InvalidationBus) (*CacheNear, error) {
+	if cfg.L1TTL <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidL1TTL, cfg.L1TTL)
+	}
+	instanceID := make([]byte, 8)
+	_, _ = rand.Read(instanceID)
+	return &CacheNear{
+		L1:         l1,
+		L2:         l2,
+		Bus:        bus,
+		Config:     cfg,
+		instanceID: hex.EncodeToString(instanceID),
+	}, nil
+}
+
+// Start subscribes to invalidations broadcast by other instances.
+func (c *CacheNear) Start(ctx context.Context) error {
+	subscription, err := c.Bus.Subscribe(ctx, c.handleInvalidation)
+	if err != nil {
+		return err
+	}
+	c.subscription = subscription
+	return nil
+}
+
+// Close stops receiving invalidations.
+func (c *CacheNear) Close() error {
+	if c.subscription == nil {
+		return nil
+	}
+	return c.subscription.Close()
+}
+
+func (c *CacheNear) handleInvalidation(ctx context.Context, invalidation Invalidation) {
+	if invalidation.Source == c.instanceID {
+		return
+	}
+	c.evict(ctx, invalidation.Keys...)
+}
+
+// getEvictions retrieves the eviction sequence of key.
+func (c *CacheNear) getEvictions(key string) *atomic.Uint64 {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	return &c.evictions[hasher.Sum32()%nearEvictionStripes]
+}
+
+func (c *CacheNear) evict(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		c.getEvictions(key).Add(1)
+		_ = c.L1.Delete(ctx, key)
+	}
+}
+
+// invalidate evicts keys from L1 and broadcasts their Invalidation.
+func (c *CacheNear) invalidate(ctx context.Context, keys ...string) error {
+	c.evict(ctx, keys...)
+	return c.Bus.Publish(ctx, Invalidation{
+		Source: c.instanceID,
+		Keys:   keys,
+	})
+}
+
+// write runs a write operation against L2, invalidating keys if applied.
+func (c *CacheNear) write(ctx context.Context, err error, keys ...string) error {
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		return err
+	}
+	return errors.Join(err, c.invalidate(ctx, keys...))
+}
+
+func (c *CacheNear) Set(ctx context.Context, key string, value []byte) error {
+	return c.write(ctx, c.L2.Set(ctx, key, value), key)
+}
+
+func (c *CacheNear) SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.write(ctx, c.L2.SetWithTTL(ctx, key, value, ttl), key)
+}
+
+func (c *CacheNear) SetMany(ctx context.Context, keyValues map[string][]byte) error {
+	keys := make([]string, 0, len(keyValues))
+	for key := range keyValues {
+		keys = append(keys, key)
+	}
+	return c.write(ctx, c.L2.SetMany(ctx, keyValues), keys...)
+}
+
+func (c *CacheNear) SetIfNotExists(ctx context.Context, key string, value []byte,
+	ttl time.Duration) (bool, error) {
+	ok, err := c.L2.SetIfNotExists(ctx, key, value, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return ok, c.invalidate(ctx, key)
+}
+
+func (c *CacheNear) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte) (bool, error) {
+	ok, err := c.L2.CompareAndSwap(ctx, key, oldValue, newValue)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return ok, c.invalidate(ctx, key)
+}
+
+func (c *CacheNear) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	n, err := c.L2.Increment(ctx, key, delta)
+	if err != nil || delta == 0 {
+		// zero deltas read the counter, except allocating missing ones to zero which no L1 entry holds
+		return n, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *CacheNear) Append(ctx context.Context, key string, value []byte) error {
+	return c.write(ctx, c.L2.Append(ctx, key, value), key)
+}
+
+func (c *CacheNear) Add(ctx context.Context, key string, value []byte) error {
+	return c.write(ctx, c.L2.Add(ctx, key, value), key)
+}
+
+func (c *CacheNear) List(ctx context.Context, key string) ([][]byte, error) {
+	return c.L2.List(ctx, key)
+}
+
+func (c *CacheNear) Remove(ctx context.Context, key string, value []byte) error {
+	return c.write(ctx, c.L2.Remove(ctx, key, value), key)
+}
+
+func (c *CacheNear) Len(ctx context.Context, key string) (int64, error) {
+	return c.L2.Len(ctx, key)
+}
+
+func (c *CacheNear) Trim(ctx context.Context, key string, maxLen int64) error {
+	return c.write(ctx, c.L2.Trim(ctx, key, maxLen), key)
+}
+
+func (c *CacheNear) Get(ctx context.Context, key string) ([]byte, error) {
+	if value, err := c.L1.Get(ctx, key); err == nil {
+		c.l1Hits.Add(1)
+		return value, nil
+	}
+
+	evictions := c.getEvictions(key)
+	sequence := evictions.Load()
+	value, err := c.L2.Get(ctx, key)
+	if errors.Is(err, ErrCacheMiss) {
+		c.misses.Add(1)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	c.l2Hits.Add(1)
+
+	ttl := c.Config.L1TTL
+	if remaining, errTTL := c.L2.TTL(ctx, key); errTTL == nil && remaining > 0 && remaining < ttl {
+		ttl = remaining
+	}
+	if evictions.Load() != sequence {
+		return value, nil
+	}
+	_ = c.L1.SetWithTTL(ctx, key, value, ttl)
+	// evictions racing with the population were not applied to the populated entry
+	if evictions.Load() != sequence {
+		_ = c.L1.Delete(ctx, key)
+	}
+	return value, nil
+}
+
+func (c *CacheNear) Touch(ctx context.Context, key string, ttl time.Duration) error {
+	return c.write(ctx, c.L2.Touch(ctx, key, ttl), key)
+}
+
+func (c *CacheNear) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.L2.TTL(ctx, key)
+}
+
+func (c *CacheNear) Delete(ctx context.Context, key string) error {
+	return c.write(ctx, c.L2.Delete(ctx, key), key)
+}
+
+func (c *CacheNear) DeleteMany(ctx context.Context, keys []string) error {
+	return c.write(ctx, c.L2.DeleteMany(ctx, keys), keys...)
+}
+
+// Stats retrieves a snapshot of hit statistics.
+func (c *CacheNear) Stats() NearCacheStats {
+	stats := NearCacheStats{
+		L1Hits: c.l1Hits.Load(),
+		L2Hits: c.l2Hits.Load(),
+		Misses: c.misses.Load(),
+	}
+	if total := stats.L1Hits + stats.L2Hits + stats.Misses; total > 0 {
+		stats.L1HitRatio = float64(stats.L1Hits) / float64(total)
+		stats.L2HitRatio = float64(stats.L2Hits) / float64(total)
+	}
+	return stats
+}
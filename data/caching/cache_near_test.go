@@ -0,0 +1,169 @@
+package caching_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+func newCacheNearTest(t *testing.T, l2 caching.Cache, bus caching.InvalidationBus) *caching.CacheNear {
+	cache, err := caching.NewCacheNear(caching.NearCacheConfig{L1TTL: time.Minute}, newCacheEmbeddedTest(t), l2,
+		bus)
+	require.NoError(t, err)
+	require.NoError(t, cache.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = cache.Close()
+	})
+	return cache
+}
+
+func TestNewCacheNear(t *testing.T) {
+	_, err := caching.NewCacheNear(caching.NearCacheConfig{}, newCacheEmbeddedTest(t), newCacheEmbeddedTest(t),
+		caching.NewInvalidationBusLocal())
+	assert.ErrorIs(t, err, caching.ErrInvalidL1TTL)
+}
+
+func TestCacheNear(t *testing.T) {
+	ctx := context.Background()
+	l2 := newCacheEmbeddedTest(t)
+	bus := caching.NewInvalidationBusLocal()
+	a := newCacheNearTest(t, l2, bus)
+	b := newCacheNearTest(t, l2, bus)
+
+	_, err := a.Get(ctx, "foo")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+
+	require.NoError(t, a.Set(ctx, "foo", []byte("bar")))
+	got, err := b.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), got)
+	got, err = b.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), got)
+	assert.Equal(t, caching.NearCacheStats{L1Hits: 1, L2Hits: 1, L1HitRatio: 0.5, L2HitRatio: 0.5}, b.Stats())
+	state, err := caching.NewNearActuator(b).State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusUp, state.Status)
+	assert.Equal(t, 0.5, state.Details.(map[string]any)["l1_hit_ratio"])
+
+	// write from a evicts b's local entry
+	require.NoError(t, a.Set(ctx, "foo", []byte("baz")))
+	got, err = b.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("baz"), got)
+
+	require.NoError(t, b.Delete(ctx, "foo"))
+	_, err = a.Get(ctx, "foo")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+	_, err = b.Get(ctx, "foo")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+
+	t.Run("l1 expiration bounded by l2", func(t *testing.T) {
+		require.NoError(t, a.SetWithTTL(ctx, "short", []byte("lived"), 50*time.Millisecond))
+		_, err = b.Get(ctx, "short")
+		require.NoError(t, err)
+		time.Sleep(60 * time.Millisecond)
+		_, err = b.Get(ctx, "short")
+		assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+	})
+
+	t.Run("lists", func(t *testing.T) {
+		require.NoError(t, a.Add(ctx, "list", []byte("1")))
+		require.NoError(t, b.Add(ctx, "list", []byte("2")))
+		items, err := a.List(ctx, "list")
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte("1"), []byte("2")}, items)
+	})
+}
+
+type publishCountingBusTest struct {
+	caching.InvalidationBus
+	publishes int
+}
+
+func (b *publishCountingBusTest) Publish(ctx context.Context, invalidation caching.Invalidation) error {
+	b.publishes++
+	return b.InvalidationBus.Publish(ctx, invalidation)
+}
+
+type getHookCacheTest struct {
+	caching.Cache
+	onGet func()
+}
+
+func (c *getHookCacheTest) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.Cache.Get(ctx, key)
+	if c.onGet != nil {
+		c.onGet()
+	}
+	return value, err
+}
+
+func TestCacheNear_Increment(t *testing.T) {
+	ctx := context.Background()
+	bus := &publishCountingBusTest{InvalidationBus: caching.NewInvalidationBusLocal()}
+	cache := newCacheNearTest(t, newCacheEmbeddedTest(t), bus)
+
+	n, err := cache.Increment(ctx, "counter", 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, 1, bus.publishes)
+	// zero deltas read the counter without broadcasting invalidations
+	n, err = cache.Increment(ctx, "counter", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, 1, bus.publishes)
+}
+
+func TestCacheNear_EvictionDuringPopulation(t *testing.T) {
+	ctx := context.Background()
+	l2 := &getHookCacheTest{Cache: newCacheEmbeddedTest(t)}
+	cache := newCacheNearTest(t, l2, caching.NewInvalidationBusLocal())
+	require.NoError(t, cache.Set(ctx, "foo", []byte("old")))
+
+	// key is written (hence evicted) after L2 was read but before L1 is populated
+	l2.onGet = func() {
+		l2.onGet = nil
+		require.NoError(t, cache.Set(ctx, "foo", []byte("new")))
+	}
+	got, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), got)
+	got, err = cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), got)
+}
+
+func TestInvalidationBusRedis(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	bus := caching.NewInvalidationBusRedis(client, caching.NearCacheConfig{InvalidationChannel: "invalidations"})
+
+	received := make(chan caching.Invalidation, 1)
+	sub, err := bus.Subscribe(ctx, func(_ context.Context, invalidation caching.Invalidation) {
+		received <- invalidation
+	})
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, bus.Publish(ctx, caching.Invalidation{Source: "a", Keys: []string{"foo"}}))
+	select {
+	case invalidation := <-received:
+		assert.Equal(t, caching.Invalidation{Source: "a", Keys: []string{"foo"}}, invalidation)
+	case <-time.After(time.Second):
+		t.Fatal("invalidation not received")
+	}
+}
@@ -0,0 +1,252 @@
+package caching
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CacheRedis is the Redis implementation of Cache. Lists (Add, List) are stored as Redis lists.
+//
+// Entries expire after ItemTTL unless written with SetWithTTL. Appending to an existing entry (Append, Add)
+// keeps its expiration. Entries never expire if ItemTTL is zero.
+type CacheRedis struct {
+	Client  redis.UniversalClient
+	ItemTTL time.Duration
+}
+
+var _ Cache = (*CacheRedis)(nil)
+
+func NewCacheRedis(client redis.UniversalClient, cfg RedisConfig) CacheRedis {
+	return CacheRedis{
+		Client:  client,
+		ItemTTL: cfg.ItemTTL,
+	}
+}
+
+func (c CacheRedis) Set(ctx context.Context, key string, value []byte) error {
+	return c.SetWithTTL(ctx, key, value, c.ItemTTL)
+}
+
+func (c CacheRedis) SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.Client.Set(ctx, key, value, ttl).Err()
+}
+
+// SetMany stores keyValues atomically (MULTI/EXEC). Within a Redis Cluster, keys must belong to the same
+// hash slot (e.g. using hash tags).
+func (c CacheRedis) SetMany(ctx context.Context, keyValues map[string][]byte) error {
+	_, err := c.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for k, v := range keyValues {
+			pipe.Set(ctx, k, v, c.ItemTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+func (c CacheRedis) SetIfNotExists(ctx context.Context, key string, value []byte,
+	ttl time.Duration) (bool, error) {
+	return c.Client.SetNX(ctx, key, value, ttl).Result()
+}
+
+// compareAndSwapScriptRedis replaces the value of a key (ARGV[2]) if equal to ARGV[1], keeping its
+// expiration. Returns -1 if key is missing, 0 if values differ, 1 if replaced.
+var compareAndSwapScriptRedis = redis.NewScript(`
+local v = redis.call('GET', KEYS[1])
+if v == false then
+	return -1
+elseif v ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'KEEPTTL')
+return 1
+`)
+
+func (c CacheRedis) CompareAndSwap(ctx context.Context, key string, oldValue, newValue []byte) (bool, error) {
+	res, err := compareAndSwapScriptRedis.Run(ctx, c.Client, []string{key}, oldValue, newValue).Int()
+	if err != nil {
+		return false, err
+	} else if res < 0 {
+		return false, ErrCacheMiss
+	}
+	return res == 1, nil
+}
+
+// incrementScriptRedis increments a key by ARGV[1] (INCRBY), setting expiration (ARGV[2]) only if the key
+// was created.
+var incrementScriptRedis = redis.NewScript(`
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+if created and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return n
+`)
+
+func (c CacheRedis) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	n, err := incrementScriptRedis.Run(ctx, c.Client, []string{key}, delta, c.ItemTTL.Milliseconds()).Int64()
+	if err != nil && strings.Contains(err.Error(), "not an integer") {
+		return 0, ErrNotInteger
+	}
+	return n, err
+}
+
+// appendScriptRedis runs an append command (ARGV[3]), setting expiration (ARGV[2]) only if the command
+// created the key, detected by its resulting length (ARGV[4]). Existing keys keep their expiration.
+var appendScriptRedis = redis.NewScript(`
+local n = redis.call(ARGV[3], KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and n == tonumber(ARGV[4]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return n
+`)
+
+func (c CacheRedis) Append(ctx context.Context, key string, value []byte) error {
+	return appendScriptRedis.Run(ctx, c.Client, []string{key}, value, c.ItemTTL.Milliseconds(), "APPEND",
+		len(value)).Err()
+}
+
+func (c CacheRedis) Add(ctx context.Context, key string, value []byte) error {
+	return appendScriptRedis.Run(ctx, c.Client, []string{key}, value, c.ItemTTL.Milliseconds(), "RPUSH",
+		1).Err()
+}
+
+func (c CacheRedis) List(ctx context.Context, key string) ([][]byte, error) {
+	items, err := c.Client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	} else if len(items) == 0 {
+		// Redis removes empty lists
+		return nil, ErrCacheMiss
+	}
+
+	out := make([][]byte, 0, len(items))
+	for _, item := range items {
+		out = append(out, []byte(item))
+	}
+	return out, nil
+}
+
+func (c CacheRedis) Remove(ctx context.Context, key string, value []byte) error {
+	removed, err := c.Client.LRem(ctx, key, 0, value).Result()
+	if err != nil {
+		return err
+	} else if removed == 0 {
+		return ErrCacheMiss
+	}
+	return nil
+}
+
+func (c CacheRedis) Len(ctx context.Context, key string) (int64, error) {
+	n, err := c.Client.LLen(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, ErrCacheMiss
+	}
+	return n, nil
+}
+
+func (c CacheRedis) Trim(ctx context.Context, key string, maxLen int64) error {
+	if maxLen < 1 {
+		return c.Delete(ctx, key)
+	}
+
+	var exists *redis.IntCmd
+	_, err := c.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, key)
+		pipe.LTrim(ctx, key, -maxLen, -1)
+		return nil
+	})
+	if err != nil {
+		return err
+	} else if exists.Val() == 0 {
+		return ErrCacheMiss
+	}
+	return nil
+}
+
+func (c CacheRedis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.Client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return value, err
+}
+
+func (c CacheRedis) Touch(ctx context.Context, key string, ttl time.Duration) error {
+	if ttl > 0 {
+		ok, err := c.Client.PExpire(ctx, key, ttl).Result()
+		if err != nil {
+			return err
+		} else if !ok {
+			return ErrCacheMiss
+		}
+		return nil
+	}
+
+	var exists *redis.IntCmd
+	_, err := c.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, key)
+		pipe.Persist(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	} else if exists.Val() == 0 {
+		return ErrCacheMiss
+	}
+	return nil
+}
+
+func (c CacheRedis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.Client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch ttl {
+	case -2:
+		return 0, ErrCacheMiss
+	case -1:
+		return NoExpiration, nil
+	default:
+		return ttl, nil
+	}
+}
+
+func (c CacheRedis) Delete(ctx context.Context, key string) error {
+	deleted, err := c.Client.Del(ctx, key).Result()
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrCacheMiss
+	}
+	return nil
+}
+
+// DeleteMany removes keys using pipelined DEL commands, so keys are not required to belong to the same
+// Redis Cluster hash slot.
+func (c CacheRedis) DeleteMany(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	cmds, err := c.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0, len(keys))
+	for _, cmd := range cmds {
+		if deleted, ok := cmd.(*redis.IntCmd); ok && deleted.Val() == 0 {
+			errs = append(errs, ErrCacheMiss)
+		}
+	}
+	return errors.Join(errs...)
+}
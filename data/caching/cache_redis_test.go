@@ -0,0 +1,75 @@
+package caching_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+func newCacheRedisTest(t *testing.T) (caching.CacheRedis, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return caching.NewCacheRedis(client, caching.RedisConfig{ItemTTL: time.Minute}), server
+}
+
+func TestCacheRedis(t *testing.T) {
+	ctx := context.Background()
+	cache, server := newCacheRedisTest(t)
+
+	require.NoError(t, cache.Set(ctx, "foo", []byte("bar")))
+	got, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar"), got)
+	assert.Equal(t, time.Minute, server.TTL("foo"))
+
+	require.NoError(t, cache.SetMany(ctx, map[string][]byte{"a": []byte("1"), "b": []byte("2")}))
+	assert.Equal(t, time.Minute, server.TTL("b"))
+
+	require.NoError(t, cache.Append(ctx, "a", []byte("23")))
+	got, err = cache.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("123"), got)
+
+	require.NoError(t, cache.Add(ctx, "list", []byte("x")))
+	require.NoError(t, cache.Add(ctx, "list", []byte("y\nz")))
+	items, err := cache.List(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("x"), []byte("y\nz")}, items)
+	assert.Equal(t, time.Minute, server.TTL("list"))
+
+	require.NoError(t, cache.DeleteMany(ctx, []string{"a", "b", "list"}))
+	assert.False(t, server.Exists("a"))
+	assert.False(t, server.Exists("list"))
+
+	require.NoError(t, cache.Delete(ctx, "foo"))
+	_, err = cache.Get(ctx, "foo")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+
+	server.FastForward(2 * time.Minute)
+	assert.False(t, server.Exists("b"))
+}
+
+func TestRedisActuator_State(t *testing.T) {
+	cache, server := newCacheRedisTest(t)
+	act := caching.NewRedisActuator(cache.Client)
+
+	state, err := act.State(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusUp, state.Status)
+
+	server.Close()
+	state, err = act.State(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusDown, state.Status)
+}
@@ -0,0 +1,28 @@
+package caching_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/data/caching/cachingtest"
+)
+
+func TestCacheEmbedded_Suite(t *testing.T) {
+	cachingtest.RunCacheSuite(t, func(t *testing.T) (caching.Cache, func(time.Duration)) {
+		return newCacheEmbeddedTest(t), time.Sleep
+	})
+}
+
+func TestCacheRedis_Suite(t *testing.T) {
+	cachingtest.RunCacheSuite(t, func(t *testing.T) (caching.Cache, func(time.Duration)) {
+		cache, server := newCacheRedisTest(t)
+		return cache, server.FastForward
+	})
+}
+
+func TestCacheNear_Suite(t *testing.T) {
+	cachingtest.RunCacheSuite(t, func(t *testing.T) (caching.Cache, func(time.Duration)) {
+		return newCacheNearTest(t, newCacheEmbeddedTest(t), caching.NewInvalidationBusLocal()), time.Sleep
+	})
+}
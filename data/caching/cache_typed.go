@@ -0,0 +1,181 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LoaderFunc loads the value of key from its source of truth.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// TypedCache a Cache wrapper storing values of type V, encoded with a Codec.
+//
+// Keys are formatted as KeyPrefix + fmt.Sprint(key). Values are read through (GetOrLoad) using Policy; a
+// non-zero Policy stores values along with their freshness metadata, so caches sharing a KeyPrefix must use
+// the same Policy.
+type TypedCache[K comparable, V any] struct {
+	Cache     Cache
+	Codec     Codec
+	KeyPrefix string
+	Policy    Policy
+
+	group singleflight.Group
+}
+
+// NewTypedCache allocates a TypedCache instance.
+func NewTypedCache[K comparable, V any](cache Cache, codec Codec, keyPrefix string) *TypedCache[K, V] {
+	return &TypedCache[K, V]{
+		Cache:     cache,
+		Codec:     codec,
+		KeyPrefix: keyPrefix,
+	}
+}
+
+func (t *TypedCache[K, V]) formatKey(key K) string {
+	return t.KeyPrefix + fmt.Sprint(key)
+}
+
+// Get retrieves the value of key. Values cached by Policy as stale are still retrieved, while cached not
+// found results are reported as ErrCacheMiss.
+func (t *TypedCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	var value V
+	data, err := t.Cache.Get(ctx, t.formatKey(key))
+	if err != nil {
+		return value, err
+	} else if !t.Policy.IsZero() {
+		entry, errDecode := decodePolicyEntry(data)
+		if errDecode != nil {
+			return value, errDecode
+		} else if entry.negative {
+			return value, ErrCacheMiss
+		}
+		data = entry.payload
+	}
+	err = t.Codec.Unmarshal(data, &value)
+	return value, err
+}
+
+// Set stores value for key.
+func (t *TypedCache[K, V]) Set(ctx context.Context, key K, value V) error {
+	return t.set(ctx, key, value, 0)
+}
+
+// set stores value for key, loaded in delta.
+func (t *TypedCache[K, V]) set(ctx context.Context, key K, value V, delta time.Duration) error {
+	data, err := t.Codec.Marshal(value)
+	if err != nil {
+		return err
+	} else if t.Policy.IsZero() {
+		return t.Cache.Set(ctx, t.formatKey(key), data)
+	}
+
+	entry := policyEntry{
+		delta:   delta,
+		payload: data,
+	}
+	if t.Policy.TTL <= 0 {
+		return t.Cache.Set(ctx, t.formatKey(key), entry.encode())
+	}
+	entry.freshUntil = time.Now().Add(t.Policy.TTL)
+	return t.Cache.SetWithTTL(ctx, t.formatKey(key), entry.encode(), t.Policy.TTL+t.Policy.StaleTTL)
+}
+
+// setNotFound stores the not found result err for key.
+func (t *TypedCache[K, V]) setNotFound(ctx context.Context, key K, err error) error {
+	payload, errMarshal := json.Marshal(newPolicyNegativeEntry(err))
+	if errMarshal != nil {
+		return errMarshal
+	}
+	entry := policyEntry{
+		negative:   true,
+		freshUntil: time.Now().Add(t.Policy.NegativeTTL),
+		payload:    payload,
+	}
+	return t.Cache.SetWithTTL(ctx, t.formatKey(key), entry.encode(), t.Policy.NegativeTTL)
+}
+
+// Delete removes key.
+func (t *TypedCache[K, V]) Delete(ctx context.Context, key K) error {
+	return t.Cache.Delete(ctx, t.formatKey(key))
+}
+
+// GetOrLoad retrieves the value of key, loading it with loader and storing it if missing (read-through).
+//
+// Concurrent misses of the same key share a single loader call. The load is detached from the
+// cancellation of ctx, so a cancelled caller does not fail others waiting for the same key; callers stop
+// waiting once their own ctx is done.
+//
+// Following Policy, stale values (or fresh values expiring early) are served while being refreshed in
+// background, and not found results are served until Policy.NegativeTTL elapses.
+//
+// Cache failures (e.g. unavailable backend or values failing to decode) are treated as misses, and failing
+// to store a loaded value is not reported as the value is still valid.
+func (t *TypedCache[K, V]) GetOrLoad(ctx context.Context, key K, loader LoaderFunc[K, V]) (V, error) {
+	if t.Policy.IsZero() {
+		if value, err := t.Get(ctx, key); err == nil {
+			return value, nil
+		}
+	} else if value, ok, err := t.getWithPolicy(ctx, key, loader); ok {
+		return value, err
+	}
+
+	resultCh := t.load(ctx, key, loader)
+	select {
+	case res := <-resultCh:
+		loaded, _ := res.Val.(V)
+		return loaded, res.Err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// getWithPolicy serves the cached result of key following Policy. Returns false if key must be loaded.
+func (t *TypedCache[K, V]) getWithPolicy(ctx context.Context, key K, loader LoaderFunc[K, V]) (V, bool, error) {
+	var value V
+	data, err := t.Cache.Get(ctx, t.formatKey(key))
+	if err != nil {
+		return value, false, nil
+	}
+	entry, err := decodePolicyEntry(data)
+	if err != nil {
+		return value, false, nil
+	}
+
+	now := time.Now()
+	fresh := entry.isFresh(now)
+	if entry.negative {
+		return value, fresh, decodeNegativeError(entry.payload)
+	} else if !fresh && !now.Before(entry.freshUntil.Add(t.Policy.StaleTTL)) {
+		return value, false, nil
+	} else if err = t.Codec.Unmarshal(entry.payload, &value); err != nil {
+		return value, false, nil
+	}
+
+	if !fresh || t.Policy.shouldRefreshEarly(entry, now) {
+		// result is not awaited, DoChan channels are buffered
+		_ = t.load(ctx, key, loader)
+	}
+	return value, true, nil
+}
+
+// load loads key using loader, sharing the call with concurrent loads of the same key.
+func (t *TypedCache[K, V]) load(ctx context.Context, key K, loader LoaderFunc[K, V]) <-chan singleflight.Result {
+	loadCtx := context.WithoutCancel(ctx)
+	return t.group.DoChan(t.formatKey(key), func() (any, error) {
+		startedAt := time.Now()
+		loaded, err := loader(loadCtx, key)
+		if err != nil {
+			if t.Policy.NegativeTTL > 0 && isNotFoundError(err) {
+				_ = t.setNotFound(loadCtx, key, err)
+			}
+			return loaded, err
+		}
+		_ = t.set(loadCtx, key, loaded, time.Since(startedAt))
+		return loaded, nil
+	})
+}
@@ -0,0 +1,132 @@
+package caching_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+type userTest struct {
+	ID   string
+	Name string
+	Age  int32
+}
+
+type pointTest struct {
+	X, Y int32
+}
+
+func newCacheEmbeddedTest(t *testing.T) caching.CacheEmbedded {
+	db, err := bigcache.New(context.Background(), bigcache.DefaultConfig(time.Minute))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return caching.NewCacheEmbedded(db)
+}
+
+func TestCodec(t *testing.T) {
+	user := userTest{ID: "1", Name: "alice", Age: 30}
+	for name, codec := range map[string]caching.Codec{
+		"json": caching.CodecJSON{},
+		"gob":  caching.CodecGob{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(user)
+			require.NoError(t, err)
+			var got userTest
+			require.NoError(t, codec.Unmarshal(data, &got))
+			assert.Equal(t, user, got)
+		})
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		codec := caching.CodecBinary{}
+		data, err := codec.Marshal(pointTest{X: 1, Y: -2})
+		require.NoError(t, err)
+		assert.Len(t, data, 8)
+		var point pointTest
+		require.NoError(t, codec.Unmarshal(data, &point))
+		assert.Equal(t, pointTest{X: 1, Y: -2}, point)
+
+		data, err = codec.Marshal("foo")
+		require.NoError(t, err)
+		var str string
+		require.NoError(t, codec.Unmarshal(data, &str))
+		assert.Equal(t, "foo", str)
+
+		ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		data, err = codec.Marshal(ts)
+		require.NoError(t, err)
+		var gotTs time.Time
+		require.NoError(t, codec.Unmarshal(data, &gotTs))
+		assert.True(t, ts.Equal(gotTs))
+
+		_, err = codec.Marshal(user)
+		assert.ErrorIs(t, err, caching.ErrUnsupportedCodecType)
+	})
+}
+
+func TestTypedCache_GetOrLoad(t *testing.T) {
+	cache := caching.NewTypedCache[string, userTest](newCacheEmbeddedTest(t), caching.CodecJSON{}, "user:")
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (userTest, error) {
+		calls.Add(1)
+		<-release
+		return userTest{ID: key, Name: "alice"}, nil
+	}
+
+	const callers = 10
+	wg := sync.WaitGroup{}
+	results := make([]userTest, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = cache.GetOrLoad(context.Background(), "1", loader)
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, userTest{ID: "1", Name: "alice"}, results[i])
+	}
+
+	// stored by read-through
+	got, err := cache.Get(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Name)
+	_, err = cache.GetOrLoad(context.Background(), "1", loader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+
+	errLoad := errors.New("not found")
+	_, err = cache.GetOrLoad(context.Background(), "2", func(ctx context.Context, key string) (userTest, error) {
+		return userTest{}, errLoad
+	})
+	assert.ErrorIs(t, err, errLoad)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cache.GetOrLoad(ctx, "3", func(ctx context.Context, key string) (userTest, error) {
+		time.Sleep(10 * time.Millisecond)
+		return userTest{}, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
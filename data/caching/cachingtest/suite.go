@@ -0,0 +1,228 @@
+// Package cachingtest provides conformance test suites for caching implementations.
+package cachingtest
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+// NewCacheFunc allocates an empty caching.Cache under test. advance moves the clock of the cache forward
+// (e.g. time.Sleep for caches using the system clock).
+type NewCacheFunc func(t *testing.T) (cache caching.Cache, advance func(time.Duration))
+
+// RunCacheSuite verifies a caching.Cache implements the semantics documented by the caching.Cache
+// interface.
+func RunCacheSuite(t *testing.T, newCache NewCacheFunc) {
+	t.Run("values", func(t *testing.T) {
+		testValues(t, newCache)
+	})
+	t.Run("ttl", func(t *testing.T) {
+		testTTL(t, newCache)
+	})
+	t.Run("lists", func(t *testing.T) {
+		testLists(t, newCache)
+	})
+	t.Run("atomic", func(t *testing.T) {
+		testAtomic(t, newCache)
+	})
+}
+
+func testValues(t *testing.T, newCache NewCacheFunc) {
+	ctx := context.Background()
+	cache, _ := newCache(t)
+
+	_, err := cache.Get(ctx, "missing")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	assert.ErrorIs(t, cache.Delete(ctx, "missing"), caching.ErrCacheMiss)
+
+	require.NoError(t, cache.Set(ctx, "foo", []byte("bar")))
+	require.NoError(t, cache.Append(ctx, "foo", []byte("baz")))
+	got, err := cache.Get(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("barbaz"), got)
+
+	require.NoError(t, cache.SetMany(ctx, map[string][]byte{
+		"a": []byte("1"),
+		"b": []byte("2"),
+	}))
+	got, err = cache.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("2"), got)
+
+	require.NoError(t, cache.Delete(ctx, "foo"))
+	_, err = cache.Get(ctx, "foo")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	require.NoError(t, cache.DeleteMany(ctx, []string{"a"}))
+	assert.ErrorIs(t, cache.DeleteMany(ctx, []string{"b", "missing"}), caching.ErrCacheMiss)
+	_, err = cache.Get(ctx, "b")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+}
+
+func testTTL(t *testing.T, newCache NewCacheFunc) {
+	ctx := context.Background()
+	cache, advance := newCache(t)
+	const ttl = 200 * time.Millisecond
+
+	_, err := cache.TTL(ctx, "missing")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	assert.ErrorIs(t, cache.Touch(ctx, "missing", ttl), caching.ErrCacheMiss)
+
+	require.NoError(t, cache.SetWithTTL(ctx, "session", []byte("abc"), ttl))
+	require.NoError(t, cache.SetWithTTL(ctx, "reference", []byte("xyz"), 0))
+	require.NoError(t, cache.SetWithTTL(ctx, "touched", []byte("123"), ttl))
+	require.NoError(t, cache.Add(ctx, "list", []byte("a")))
+	require.NoError(t, cache.Touch(ctx, "list", ttl))
+
+	remaining, err := cache.TTL(ctx, "session")
+	require.NoError(t, err)
+	assert.Greater(t, remaining, time.Duration(0))
+	assert.LessOrEqual(t, remaining, ttl)
+	remaining, err = cache.TTL(ctx, "reference")
+	require.NoError(t, err)
+	assert.Equal(t, caching.NoExpiration, remaining)
+
+	require.NoError(t, cache.Touch(ctx, "touched", time.Hour))
+	require.NoError(t, cache.Append(ctx, "session", []byte("d")))
+	got, err := cache.Get(ctx, "session")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("abcd"), got)
+
+	advance(2 * ttl)
+
+	_, err = cache.Get(ctx, "session")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	_, err = cache.List(ctx, "list")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	got, err = cache.Get(ctx, "reference")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("xyz"), got)
+	got, err = cache.Get(ctx, "touched")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("123"), got)
+
+	require.NoError(t, cache.Touch(ctx, "touched", 0))
+	remaining, err = cache.TTL(ctx, "touched")
+	require.NoError(t, err)
+	assert.Equal(t, caching.NoExpiration, remaining)
+}
+
+func testLists(t *testing.T, newCache NewCacheFunc) {
+	ctx := context.Background()
+	cache, _ := newCache(t)
+
+	_, err := cache.List(ctx, "missing")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	_, err = cache.Len(ctx, "missing")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	assert.ErrorIs(t, cache.Remove(ctx, "missing", []byte("a")), caching.ErrCacheMiss)
+	assert.ErrorIs(t, cache.Trim(ctx, "missing", 1), caching.ErrCacheMiss)
+
+	binaryItem := []byte{0x00, '\n', 0x01, '\r', '\n'}
+	largeItem := bytes.Repeat([]byte("x"), 128*1024)
+	items := [][]byte{[]byte("a"), binaryItem, {}, largeItem, []byte("a"), []byte("b")}
+	for _, item := range items {
+		require.NoError(t, cache.Add(ctx, "list", item))
+	}
+	got, err := cache.List(ctx, "list")
+	require.NoError(t, err)
+	require.Len(t, got, len(items))
+	for i := range items {
+		assert.True(t, bytes.Equal(items[i], got[i]), "item %d", i)
+	}
+	n, err := cache.Len(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(items)), n)
+
+	require.NoError(t, cache.Remove(ctx, "list", []byte("a")))
+	assert.ErrorIs(t, cache.Remove(ctx, "list", []byte("a")), caching.ErrCacheMiss)
+	n, err = cache.Len(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	require.NoError(t, cache.Trim(ctx, "list", 10))
+	require.NoError(t, cache.Trim(ctx, "list", 2))
+	got, err = cache.List(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{largeItem, []byte("b")}, got)
+
+	// lists left empty are removed
+	require.NoError(t, cache.Remove(ctx, "list", largeItem))
+	require.NoError(t, cache.Remove(ctx, "list", []byte("b")))
+	_, err = cache.List(ctx, "list")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+
+	require.NoError(t, cache.Add(ctx, "list", []byte("c")))
+	require.NoError(t, cache.Trim(ctx, "list", 0))
+	_, err = cache.Len(ctx, "list")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+}
+
+func testAtomic(t *testing.T, newCache NewCacheFunc) {
+	ctx := context.Background()
+	cache, advance := newCache(t)
+	const ttl = 200 * time.Millisecond
+
+	ok, err := cache.SetIfNotExists(ctx, "lock", []byte("a"), ttl)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = cache.SetIfNotExists(ctx, "lock", []byte("b"), ttl)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = cache.CompareAndSwap(ctx, "missing", []byte("a"), []byte("b"))
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	ok, err = cache.CompareAndSwap(ctx, "lock", []byte("b"), []byte("c"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = cache.CompareAndSwap(ctx, "lock", []byte("a"), []byte("c"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err := cache.Get(ctx, "lock")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("c"), got)
+
+	n, err := cache.Increment(ctx, "counter", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	n, err = cache.Increment(ctx, "counter", -7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-2), n)
+	got, err = cache.Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("-2"), got)
+	_, err = cache.Increment(ctx, "lock", 1)
+	assert.ErrorIs(t, err, caching.ErrNotInteger)
+
+	const workers, increments = 8, 25
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				_, errIncr := cache.Increment(ctx, "concurrent", 1)
+				assert.NoError(t, errIncr)
+			}
+		}()
+	}
+	wg.Wait()
+	n, err = cache.Increment(ctx, "concurrent", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(workers*increments), n)
+
+	// swapped values keep their expiration, expired keys are settable again
+	advance(2 * ttl)
+	_, err = cache.Get(ctx, "lock")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+	ok, err = cache.SetIfNotExists(ctx, "lock", []byte("d"), 0)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
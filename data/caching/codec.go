@@ -0,0 +1,98 @@
+package caching
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// ErrUnsupportedCodecType the value type is not supported by a Codec.
+var ErrUnsupportedCodecType = errors.New("caching: type not supported by codec")
+
+// Codec encodes and decodes values stored in a Cache.
+type Codec interface {
+	// Marshal encodes v.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into v, a pointer.
+	Unmarshal(data []byte, v any) error
+}
+
+// CodecJSON Codec implementation using encoding/json.
+type CodecJSON struct{}
+
+var _ Codec = CodecJSON{}
+
+func (c CodecJSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (c CodecJSON) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// CodecGob Codec implementation using encoding/gob. Values are encoded independently, so each entry
+// carries its own type information.
+type CodecGob struct{}
+
+var _ Codec = CodecGob{}
+
+func (c CodecGob) Marshal(v any) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c CodecGob) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// CodecBinary compact Codec implementation for types implementing encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler, strings, byte slices and fixed-size values (e.g. numbers or structs of
+// numbers, see encoding/binary), encoded in big-endian order.
+//
+// Returns ErrUnsupportedCodecType for any other type.
+type CodecBinary struct{}
+
+var _ Codec = CodecBinary{}
+
+func (c CodecBinary) Marshal(v any) ([]byte, error) {
+	switch val := v.(type) {
+	case encoding.BinaryMarshaler:
+		return val.MarshalBinary()
+	case string:
+		return []byte(val), nil
+	case []byte:
+		return val, nil
+	}
+	if binary.Size(v) < 0 {
+		return nil, ErrUnsupportedCodecType
+	}
+	buf := bytes.Buffer{}
+	if err := binary.Write(&buf, binary.BigEndian, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c CodecBinary) Unmarshal(data []byte, v any) error {
+	switch val := v.(type) {
+	case encoding.BinaryUnmarshaler:
+		return val.UnmarshalBinary(data)
+	case *string:
+		*val = string(data)
+		return nil
+	case *[]byte:
+		*val = append((*val)[:0], data...)
+		return nil
+	}
+	if reflect.ValueOf(v).Kind() != reflect.Pointer || binary.Size(v) < 0 {
+		return ErrUnsupportedCodecType
+	}
+	return binary.Read(bytes.NewReader(data), binary.BigEndian, v)
+}
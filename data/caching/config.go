@@ -2,6 +2,83 @@ package caching
 
 import "time"
 
+// Backend a Cache implementation selectable through BackendConfig.
+type Backend string
+
+const (
+	// BackendEmbedded selects CacheEmbedded.
+	BackendEmbedded Backend = "embedded"
+	// BackendRedis selects CacheRedis.
+	BackendRedis Backend = "redis"
+	// BackendNear selects CacheNear, using CacheEmbedded as local tier and CacheRedis as remote tier.
+	BackendNear Backend = "near"
+)
+
+// BackendConfig configuration structure selecting the Cache implementation of an application.
+type BackendConfig struct {
+	// Backend one of BackendEmbedded, BackendRedis or BackendNear.
+	Backend Backend `env:"CACHE_BACKEND" envDefault:"embedded"`
+}
+
+// BigCacheConfig configuration structure for bigcache.BigCache instances (see NewBigCache).
 type BigCacheConfig struct {
+	// ItemTTL default expiration of entries written by CacheEmbedded (see CacheEmbedded.ItemTTL). Entries
+	// never expire if zero.
 	ItemTTL time.Duration `env:"BIG_CACHE_ITEM_TTL" envDefault:"5m"`
+	// Shards number of cache shards. Must be a power of two.
+	Shards int `env:"BIG_CACHE_SHARDS" envDefault:"1024"`
+	// CleanWindow interval between removals of expired entries. Expired entries are not removed if zero.
+	CleanWindow time.Duration `env:"BIG_CACHE_CLEAN_WINDOW" envDefault:"1s"`
+	// MaxEntriesInWindow number of entries expected to be stored, used to allocate shards on start.
+	MaxEntriesInWindow int `env:"BIG_CACHE_MAX_ENTRIES_IN_WINDOW" envDefault:"600000"`
+	// MaxEntrySize expected maximum entry size in bytes, used to allocate shards on start.
+	MaxEntrySize int `env:"BIG_CACHE_MAX_ENTRY_SIZE" envDefault:"500"`
+	// HardMaxCacheSize limit of the cache size, in megabytes. Oldest entries are evicted once reached. Zero
+	// means no limit.
+	HardMaxCacheSize int `env:"BIG_CACHE_HARD_MAX_CACHE_SIZE" envDefault:"0"`
+	// Verbose logs memory allocations.
+	Verbose bool `env:"BIG_CACHE_VERBOSE" envDefault:"false"`
+}
+
+// EmbeddedActuatorConfig configuration structure for EmbeddedActuator.
+type EmbeddedActuatorConfig struct {
+	// Window duration over which statistics are evaluated, so the cache recovers from past degradations.
+	Window time.Duration `env:"CACHE_ACTUATOR_WINDOW" envDefault:"1m"`
+	// MinHitRatio hit ratio under which the cache is considered degraded.
+	MinHitRatio float64 `env:"CACHE_ACTUATOR_MIN_HIT_RATIO" envDefault:"0.5"`
+	// MinLookups number of lookups (hits and misses) within Window required before evaluating MinHitRatio,
+	// so cold caches are not reported as degraded.
+	MinLookups int64 `env:"CACHE_ACTUATOR_MIN_LOOKUPS" envDefault:"100"`
+	// MaxNoSpaceEvictionRate rate of entries evicted for lack of free capacity (per second) above which the
+	// cache is considered degraded. Not evaluated if zero.
+	MaxNoSpaceEvictionRate float64 `env:"CACHE_ACTUATOR_MAX_NO_SPACE_EVICTION_RATE" envDefault:"10"`
+}
+
+// RedisConfig configuration structure for Redis clients and CacheRedis.
+type RedisConfig struct {
+	// Addresses host:port addresses of the Redis nodes. A single address uses a standalone client, multiple
+	// addresses use a cluster client (or a failover client if MasterName is set).
+	Addresses []string `env:"REDIS_ADDRESSES" envDefault:"localhost:6379"`
+	// MasterName Redis Sentinel master name.
+	MasterName string `env:"REDIS_MASTER_NAME"`
+	Username   string `env:"REDIS_USERNAME"`
+	Password   string `env:"REDIS_PASSWORD,unset"`
+	// DB database selected by standalone and failover clients.
+	DB int `env:"REDIS_DB" envDefault:"0"`
+	// PoolSize maximum number of connections per node. Zero uses the client default.
+	PoolSize     int           `env:"REDIS_POOL_SIZE" envDefault:"0"`
+	DialTimeout  time.Duration `env:"REDIS_DIAL_TIMEOUT" envDefault:"5s"`
+	ReadTimeout  time.Duration `env:"REDIS_READ_TIMEOUT" envDefault:"3s"`
+	WriteTimeout time.Duration `env:"REDIS_WRITE_TIMEOUT" envDefault:"3s"`
+	// ItemTTL expiration of entries written by CacheRedis. Entries never expire if zero.
+	ItemTTL time.Duration `env:"REDIS_ITEM_TTL" envDefault:"5m"`
+}
+
+// NearCacheConfig configuration structure for CacheNear.
+type NearCacheConfig struct {
+	// L1TTL time an entry is kept in the local tier, bounding staleness if invalidations are lost. Must be
+	// greater than zero.
+	L1TTL time.Duration `env:"NEAR_CACHE_L1_TTL" envDefault:"30s"`
+	// InvalidationChannel pub/sub channel used to broadcast invalidations.
+	InvalidationChannel string `env:"NEAR_CACHE_INVALIDATION_CHANNEL" envDefault:"geck.caching.invalidations"`
 }
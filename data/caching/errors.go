@@ -0,0 +1,16 @@
+package caching
+
+import "errors"
+
+var (
+	// ErrCacheMiss the key was not found or has expired.
+	ErrCacheMiss = errors.New("caching: cache miss")
+	// ErrMalformedList the entry of a key is not a list (e.g. written with Set or Append) or is corrupted.
+	ErrMalformedList = errors.New("caching: malformed list")
+	// ErrNotInteger the value of a key is not a base-10 64-bit integer.
+	ErrNotInteger = errors.New("caching: value is not an integer")
+	// ErrInvalidL1TTL NearCacheConfig.L1TTL is not greater than zero, so local entries would never expire.
+	ErrInvalidL1TTL = errors.New("caching: invalid near cache L1 TTL")
+	// ErrUnsupportedBackend the Backend is not supported.
+	ErrUnsupportedBackend = errors.New("caching: unsupported backend")
+)
@@ -0,0 +1,121 @@
+package caching
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Invalidation a notification of keys mutated by a cache instance.
+type Invalidation struct {
+	// Source identifier of the instance which mutated the keys.
+	Source string   `json:"source"`
+	Keys   []string `json:"keys"`
+}
+
+// InvalidationHandler handles an Invalidation received from an InvalidationBus.
+type InvalidationHandler func(ctx context.Context, invalidation Invalidation)
+
+// InvalidationBus a pub/sub channel broadcasting Invalidation messages between cache instances.
+type InvalidationBus interface {
+	// Publish broadcasts invalidation to every subscriber.
+	Publish(ctx context.Context, invalidation Invalidation) error
+	// Subscribe registers handler, called for each published Invalidation until the returned io.Closer is
+	// closed.
+	Subscribe(ctx context.Context, handler InvalidationHandler) (io.Closer, error)
+}
+
+// InvalidationBusLocal is the in-process implementation of InvalidationBus, handlers are called
+// synchronously. Aimed for testing and instances sharing a process.
+type InvalidationBusLocal struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[int]InvalidationHandler
+}
+
+var _ InvalidationBus = (*InvalidationBusLocal)(nil)
+
+func NewInvalidationBusLocal() *InvalidationBusLocal {
+	return &InvalidationBusLocal{
+		handlers: map[int]InvalidationHandler{},
+	}
+}
+
+func (b *InvalidationBusLocal) Publish(ctx context.Context, invalidation Invalidation) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.handlers {
+		handler(ctx, invalidation)
+	}
+	return nil
+}
+
+func (b *InvalidationBusLocal) Subscribe(_ context.Context, handler InvalidationHandler) (io.Closer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.handlers[id] = handler
+	return closerFunc(func() error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+		return nil
+	}), nil
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// InvalidationBusRedis is the Redis Pub/Sub implementation of InvalidationBus. Invalidation messages are
+// encoded as JSON.
+//
+// Redis Pub/Sub delivers at most once; messages published while a subscriber is reconnecting are lost.
+type InvalidationBusRedis struct {
+	Client  redis.UniversalClient
+	Channel string
+}
+
+var _ InvalidationBus = (*InvalidationBusRedis)(nil)
+
+func NewInvalidationBusRedis(client redis.UniversalClient, cfg NearCacheConfig) InvalidationBusRedis {
+	return InvalidationBusRedis{
+		Client:  client,
+		Channel: cfg.InvalidationChannel,
+	}
+}
+
+func (b InvalidationBusRedis) Publish(ctx context.Context, invalidation Invalidation) error {
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return err
+	}
+	return b.Client.Publish(ctx, b.Channel, payload).Err()
+}
+
+func (b InvalidationBusRedis) Subscribe(ctx context.Context, handler InvalidationHandler) (io.Closer, error) {
+	sub := b.Client.Subscribe(ctx, b.Channel)
+	// waits for subscription confirmation, so no message published afterward is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	subCtx := context.WithoutCancel(ctx)
+	go func() {
+		for msg := range sub.Channel() {
+			invalidation := Invalidation{}
+			if err := json.Unmarshal([]byte(msg.Payload), &invalidation); err != nil {
+				continue
+			}
+			handler(subCtx, invalidation)
+		}
+	}()
+	return sub, nil
+}
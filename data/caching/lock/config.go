@@ -0,0 +1,15 @@
+package lock
+
+import "time"
+
+// Config configuration structure for Locker instances.
+type Config struct {
+	// LeaseTTL time a Lease is held unless renewed.
+	LeaseTTL time.Duration `env:"LOCK_LEASE_TTL" envDefault:"30s"`
+	// RenewInterval interval between Lease renewals. Must be positive and lower than LeaseTTL.
+	RenewInterval time.Duration `env:"LOCK_RENEW_INTERVAL" envDefault:"10s"`
+	// RetryInterval interval between acquisition attempts of Locker.Acquire. Must be positive.
+	RetryInterval time.Duration `env:"LOCK_RETRY_INTERVAL" envDefault:"1s"`
+	// KeyPrefix prefix of the cache keys used by Locker.
+	KeyPrefix string `env:"LOCK_KEY_PREFIX" envDefault:"geck.lock."`
+}
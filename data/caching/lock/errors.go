@@ -0,0 +1,14 @@
+package lock
+
+import "errors"
+
+var (
+	// ErrLockHeld the lock is held by another owner.
+	ErrLockHeld = errors.New("lock: lock is held by another owner")
+	// ErrLeaseLost the Lease expired or was taken over before being renewed.
+	ErrLeaseLost = errors.New("lock: lease lost")
+	// ErrInvalidRenewInterval Config.RenewInterval is not positive or not lower than Config.LeaseTTL.
+	ErrInvalidRenewInterval = errors.New("lock: renew interval must be positive and lower than lease TTL")
+	// ErrInvalidRetryInterval Config.RetryInterval is not positive.
+	ErrInvalidRetryInterval = errors.New("lock: retry interval must be positive")
+)
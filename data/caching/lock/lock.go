@@ -0,0 +1,162 @@
+// Package lock provides lease-based distributed locks, aimed for leader election of replicated workloads
+// (e.g. scheduled jobs, outbox.Relay).
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// leaseStore the backend storing leases.
+type leaseStore interface {
+	// acquire stores a lease for name owned by owner if no lease exists. Returns the fencing token of the
+	// lease and true if stored.
+	acquire(ctx context.Context, name, owner string, ttl time.Duration) (int64, bool, error)
+	// renew extends the lease of name to ttl if owned by owner. Returns false if not owned.
+	renew(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	// release removes the lease of name if owned by owner.
+	release(ctx context.Context, name, owner string) error
+}
+
+// Locker acquires lease-based locks.
+//
+// A Lease is held for Config.LeaseTTL and renewed in the background every Config.RenewInterval until
+// released. If a renewal finds the Lease taken over, or the Lease expires while renewals fail, the Lease is
+// lost and its context is cancelled with ErrLeaseLost as cause.
+//
+// Every Lease carries a fencing token, increasing with each acquisition of the same lock. Storage systems
+// mutated by lock holders should reject writes with tokens lower than the last seen one, so a holder paused
+// past its Lease expiration cannot corrupt data.
+type Locker struct {
+	Config Config
+
+	store leaseStore
+}
+
+// newLocker allocates a Locker using store. Returns ErrInvalidRenewInterval or ErrInvalidRetryInterval if
+// cfg is invalid.
+func newLocker(cfg Config, store leaseStore) (Locker, error) {
+	if cfg.RenewInterval <= 0 || cfg.RenewInterval >= cfg.LeaseTTL {
+		return Locker{}, fmt.Errorf("%w: %s (lease TTL %s)", ErrInvalidRenewInterval, cfg.RenewInterval,
+			cfg.LeaseTTL)
+	} else if cfg.RetryInterval <= 0 {
+		return Locker{}, fmt.Errorf("%w: %s", ErrInvalidRetryInterval, cfg.RetryInterval)
+	}
+	return Locker{
+		Config: cfg,
+		store:  store,
+	}, nil
+}
+
+// TryAcquire acquires the lock name. Returns ErrLockHeld if the lock is held by another owner.
+//
+// The Lease context is derived from ctx, so cancelling ctx stops renewals.
+func (l Locker) TryAcquire(ctx context.Context, name string) (*Lease, error) {
+	ownerID := make([]byte, 16)
+	if _, err := rand.Read(ownerID); err != nil {
+		return nil, err
+	}
+	owner := hex.EncodeToString(ownerID)
+	token, ok, err := l.store.acquire(ctx, name, owner, l.Config.LeaseTTL)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrLockHeld
+	}
+
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	lease := &Lease{
+		Name:   name,
+		Token:  token,
+		ctx:    leaseCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		owner:  owner,
+		locker: l,
+	}
+	go lease.renew()
+	return lease, nil
+}
+
+// Acquire acquires the lock name, waiting Config.RetryInterval between attempts while held by another
+// owner, until ctx is done.
+func (l Locker) Acquire(ctx context.Context, name string) (*Lease, error) {
+	for {
+		lease, err := l.TryAcquire(ctx, name)
+		if !errors.Is(err, ErrLockHeld) {
+			return lease, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.Config.RetryInterval):
+		}
+	}
+}
+
+// Lease a lock held by a Locker.
+type Lease struct {
+	// Name name of the lock.
+	Name string
+	// Token fencing token of the Lease.
+	Token int64
+
+	ctx         context.Context
+	cancel      context.CancelCauseFunc
+	done        chan struct{}
+	owner       string
+	locker      Locker
+	releaseOnce sync.Once
+	releaseErr  error
+}
+
+// Context retrieves a context cancelled once the Lease is lost (ErrLeaseLost cause) or released. Work
+// guarded by the lock must stop once done.
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+func (l *Lease) renew() {
+	defer close(l.done)
+	cfg := l.locker.Config
+	ticker := time.NewTicker(cfg.RenewInterval)
+	defer ticker.Stop()
+	expiresAt := time.Now().Add(cfg.LeaseTTL)
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewedAt := time.Now()
+		ok, err := l.locker.store.renew(l.ctx, l.Name, l.owner, cfg.LeaseTTL)
+		switch {
+		case err == nil && ok:
+			expiresAt = renewedAt.Add(cfg.LeaseTTL)
+		case err == nil && !ok, !time.Now().Before(expiresAt):
+			l.cancel(ErrLeaseLost)
+			return
+		}
+	}
+}
+
+// Release stops renewals and releases the lock if still owned. Returns ErrLeaseLost if the Lease was lost.
+func (l *Lease) Release(ctx context.Context) error {
+	l.releaseOnce.Do(func() {
+		l.cancel(context.Canceled)
+		<-l.done
+		if errors.Is(context.Cause(l.ctx), ErrLeaseLost) {
+			l.releaseErr = ErrLeaseLost
+			return
+		}
+		l.releaseErr = l.locker.store.release(ctx, l.Name, l.owner)
+	})
+	return l.releaseErr
+}
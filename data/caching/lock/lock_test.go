@@ -0,0 +1,187 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/allegro/bigcache/v3"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/data/caching/lock"
+)
+
+var configTest = lock.Config{
+	LeaseTTL:      150 * time.Millisecond,
+	RenewInterval: 30 * time.Millisecond,
+	RetryInterval: 10 * time.Millisecond,
+	KeyPrefix:     "lock.",
+}
+
+func newCacheRedisTest(t *testing.T) caching.Cache {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return caching.NewCacheRedis(client, caching.RedisConfig{ItemTTL: time.Minute})
+}
+
+func newCacheEmbeddedTest(t *testing.T) caching.Cache {
+	db, err := bigcache.New(context.Background(), bigcache.DefaultConfig(time.Minute))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return caching.NewCacheEmbedded(db)
+}
+
+func TestLocker(t *testing.T) {
+	tests := []struct {
+		name      string
+		newLocker func(t *testing.T) lock.Locker
+	}{
+		{
+			name: "embedded",
+			newLocker: func(t *testing.T) lock.Locker {
+				locker, err := lock.NewLockerEmbedded(configTest)
+				require.NoError(t, err)
+				return locker
+			},
+		},
+		{
+			name: "cache embedded",
+			newLocker: func(t *testing.T) lock.Locker {
+				locker, err := lock.NewLocker(configTest, newCacheEmbeddedTest(t))
+				require.NoError(t, err)
+				return locker
+			},
+		},
+		{
+			name: "cache redis",
+			newLocker: func(t *testing.T) lock.Locker {
+				locker, err := lock.NewLocker(configTest, newCacheRedisTest(t))
+				require.NoError(t, err)
+				return locker
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			locker := tt.newLocker(t)
+
+			lease, err := locker.TryAcquire(ctx, "job")
+			require.NoError(t, err)
+			_, err = locker.TryAcquire(ctx, "job")
+			assert.ErrorIs(t, err, lock.ErrLockHeld)
+			other, err := locker.TryAcquire(ctx, "other-job")
+			require.NoError(t, err)
+			require.NoError(t, other.Release(ctx))
+
+			// renewals keep the lease past its TTL
+			time.Sleep(2 * configTest.LeaseTTL)
+			require.NoError(t, lease.Context().Err())
+			_, err = locker.TryAcquire(ctx, "job")
+			assert.ErrorIs(t, err, lock.ErrLockHeld)
+
+			// waiting acquisitions succeed once released, with a greater fencing token
+			acquired := make(chan *lock.Lease, 1)
+			go func() {
+				next, errAcquire := locker.Acquire(ctx, "job")
+				assert.NoError(t, errAcquire)
+				acquired <- next
+			}()
+			time.Sleep(3 * configTest.RetryInterval)
+			require.NoError(t, lease.Release(ctx))
+			assert.ErrorIs(t, context.Cause(lease.Context()), context.Canceled)
+
+			var next *lock.Lease
+			select {
+			case next = <-acquired:
+			case <-time.After(time.Second):
+				t.Fatal("lock not acquired")
+			}
+			assert.Greater(t, next.Token, lease.Token)
+			require.NoError(t, next.Release(ctx))
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, 3*configTest.RetryInterval)
+			defer cancel()
+			held, err := locker.TryAcquire(ctx, "job")
+			require.NoError(t, err)
+			_, err = locker.Acquire(timeoutCtx, "job")
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			require.NoError(t, held.Release(ctx))
+
+			// expired leases are taken over, stale owners cannot release them
+			staleCtx, cancelStale := context.WithCancel(ctx)
+			stale, err := locker.TryAcquire(staleCtx, "job")
+			require.NoError(t, err)
+			cancelStale()
+			time.Sleep(configTest.LeaseTTL + configTest.RenewInterval)
+			current, err := locker.TryAcquire(ctx, "job")
+			require.NoError(t, err)
+			require.NoError(t, stale.Release(ctx))
+			_, err = locker.TryAcquire(ctx, "job")
+			assert.ErrorIs(t, err, lock.ErrLockHeld)
+			require.NoError(t, current.Release(ctx))
+		})
+	}
+}
+
+func TestNewLocker(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(cfg *lock.Config)
+		err    error
+	}{
+		{name: "valid", mutate: func(cfg *lock.Config) {}},
+		{name: "zero renew interval", mutate: func(cfg *lock.Config) {
+			cfg.RenewInterval = 0
+		}, err: lock.ErrInvalidRenewInterval},
+		{name: "renew interval over lease TTL", mutate: func(cfg *lock.Config) {
+			cfg.RenewInterval = cfg.LeaseTTL
+		}, err: lock.ErrInvalidRenewInterval},
+		{name: "zero retry interval", mutate: func(cfg *lock.Config) {
+			cfg.RetryInterval = 0
+		}, err: lock.ErrInvalidRetryInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configTest
+			tt.mutate(&cfg)
+			_, err := lock.NewLocker(cfg, newCacheEmbeddedTest(t))
+			assert.ErrorIs(t, err, tt.err)
+			_, err = lock.NewLockerEmbedded(cfg)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestLocker_LeaseLost(t *testing.T) {
+	ctx := context.Background()
+	cache := newCacheEmbeddedTest(t)
+	locker, err := lock.NewLocker(configTest, cache)
+	require.NoError(t, err)
+
+	lease, err := locker.TryAcquire(ctx, "job")
+	require.NoError(t, err)
+	// another owner takes over the lock
+	require.NoError(t, cache.Set(ctx, "lock.job", []byte("intruder")))
+
+	select {
+	case <-lease.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("lease context not cancelled")
+	}
+	assert.True(t, errors.Is(context.Cause(lease.Context()), lock.ErrLeaseLost))
+	assert.ErrorIs(t, lease.Release(ctx), lock.ErrLeaseLost)
+	got, err := cache.Get(ctx, "lock.job")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("intruder"), got)
+}
@@ -0,0 +1,123 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+const (
+	fenceKeySuffix     = ".fence"
+	leaseCacheSplitter = "|"
+)
+
+// storeCache the caching.Cache implementation of leaseStore.
+//
+// Leases are stored under Config.KeyPrefix + name without expiration as owner + "|" + deadline (Unix
+// nanoseconds), fencing tokens are counters (caching.Cache.Increment) stored under Config.KeyPrefix + name +
+// ".fence" without expiration. Every lease transition is a caching.Cache.CompareAndSwap on the value read,
+// so ownership checks are atomic with renewals, releases (resetting the lease to an expired one) and
+// takeovers of expired leases. As deadlines are compared across instances, their clocks must be
+// synchronized within Config.LeaseTTL - Config.RenewInterval.
+type storeCache struct {
+	cache     caching.Cache
+	keyPrefix string
+}
+
+var _ leaseStore = storeCache{}
+
+// NewLocker allocates a Locker storing leases into cache. Backends evicting entries before their
+// expiration (e.g. caching.CacheEmbedded when full) might reset fencing tokens. Returns an error if cfg is
+// invalid (see Config).
+func NewLocker(cfg Config, cache caching.Cache) (Locker, error) {
+	return newLocker(cfg, storeCache{
+		cache:     cache,
+		keyPrefix: cfg.KeyPrefix,
+	})
+}
+
+func encodeLeaseCache(owner string, expiresAt time.Time) []byte {
+	return []byte(owner + leaseCacheSplitter + strconv.FormatInt(expiresAt.UnixNano(), 10))
+}
+
+// decodeLeaseCache decodes a lease value. Values not written by storeCache are considered held by an unknown
+// owner without deadline.
+func decodeLeaseCache(value []byte) (owner string, expiresAt time.Time) {
+	owner, deadline, ok := strings.Cut(string(value), leaseCacheSplitter)
+	if !ok {
+		return string(value), time.Unix(0, math.MaxInt64)
+	}
+	nanos, err := strconv.ParseInt(deadline, 10, 64)
+	if err != nil {
+		return string(value), time.Unix(0, math.MaxInt64)
+	}
+	return owner, time.Unix(0, nanos)
+}
+
+func (s storeCache) acquire(ctx context.Context, name, owner string, ttl time.Duration) (int64, bool, error) {
+	fenceKey := s.keyPrefix + name + fenceKeySuffix
+	token, err := s.cache.Increment(ctx, fenceKey, 1)
+	if err != nil {
+		return 0, false, err
+	} else if token == 1 {
+		// counters might get the backend default expiration when created
+		if err = s.cache.Touch(ctx, fenceKey, 0); err != nil {
+			return 0, false, err
+		}
+	}
+
+	key := s.keyPrefix + name
+	value := encodeLeaseCache(owner, time.Now().Add(ttl))
+	if ok, err := s.cache.SetIfNotExists(ctx, key, value, 0); err != nil || ok {
+		return token, ok, err
+	}
+	current, err := s.cache.Get(ctx, key)
+	if errors.Is(err, caching.ErrCacheMiss) {
+		// evicted meanwhile, acquisition is attempted again by the caller
+		return token, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	if _, expiresAt := decodeLeaseCache(current); time.Now().Before(expiresAt) {
+		return token, false, nil
+	}
+	ok, err := s.cache.CompareAndSwap(ctx, key, current, value)
+	if errors.Is(err, caching.ErrCacheMiss) {
+		return token, false, nil
+	}
+	return token, ok, err
+}
+
+// swap replaces the lease of name by newValue if owned by owner. Returns false if not owned.
+func (s storeCache) swap(ctx context.Context, name, owner string, newValue []byte) (bool, error) {
+	key := s.keyPrefix + name
+	current, err := s.cache.Get(ctx, key)
+	if errors.Is(err, caching.ErrCacheMiss) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if currentOwner, _ := decodeLeaseCache(current); currentOwner != owner {
+		return false, nil
+	}
+	ok, err := s.cache.CompareAndSwap(ctx, key, current, newValue)
+	if errors.Is(err, caching.ErrCacheMiss) {
+		return false, nil
+	}
+	return ok, err
+}
+
+func (s storeCache) renew(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	return s.swap(ctx, name, owner, encodeLeaseCache(owner, time.Now().Add(ttl)))
+}
+
+func (s storeCache) release(ctx context.Context, name, owner string) error {
+	// leases are expired rather than removed, as removals could not be conditioned on ownership
+	_, err := s.swap(ctx, name, owner, encodeLeaseCache("", time.Unix(0, 0)))
+	return err
+}
@@ -0,0 +1,75 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type leaseEmbedded struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// storeEmbedded the in-process implementation of leaseStore.
+type storeEmbedded struct {
+	mu     sync.Mutex
+	leases map[string]leaseEmbedded
+	fences map[string]int64
+}
+
+var _ leaseStore = (*storeEmbedded)(nil)
+
+// NewLockerEmbedded allocates a Locker storing leases in-process, aimed for single-instance deployments and
+// testing. Returns an error if cfg is invalid (see Config).
+func NewLockerEmbedded(cfg Config) (Locker, error) {
+	return newLocker(cfg, &storeEmbedded{
+		leases: map[string]leaseEmbedded{},
+		fences: map[string]int64{},
+	})
+}
+
+// getLease retrieves the lease of name, removing it if expired. Caller must hold the lock.
+func (s *storeEmbedded) getLease(name string) (leaseEmbedded, bool) {
+	lease, ok := s.leases[name]
+	if ok && !time.Now().Before(lease.expiresAt) {
+		delete(s.leases, name)
+		return leaseEmbedded{}, false
+	}
+	return lease, ok
+}
+
+func (s *storeEmbedded) acquire(_ context.Context, name, owner string, ttl time.Duration) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fences[name]++
+	if _, ok := s.getLease(name); ok {
+		return s.fences[name], false, nil
+	}
+	s.leases[name] = leaseEmbedded{
+		owner:     owner,
+		expiresAt: time.Now().Add(ttl),
+	}
+	return s.fences[name], true, nil
+}
+
+func (s *storeEmbedded) renew(_ context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lease, ok := s.getLease(name)
+	if !ok || lease.owner != owner {
+		return false, nil
+	}
+	lease.expiresAt = time.Now().Add(ttl)
+	s.leases[name] = lease
+	return true, nil
+}
+
+func (s *storeEmbedded) release(_ context.Context, name, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lease, ok := s.getLease(name); ok && lease.owner == owner {
+		delete(s.leases, name)
+	}
+	return nil
+}
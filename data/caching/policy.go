@@ -0,0 +1,138 @@
+package caching
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+// Policy the read-through policy of a TypedCache key namespace (TypedCache.KeyPrefix), protecting sources of
+// truth from cache stampedes.
+type Policy struct {
+	// TTL time a loaded value is fresh. Uses the Cache default expiration if zero, in which case values
+	// never become stale.
+	TTL time.Duration
+	// StaleTTL time a value is still served once stale, while a single goroutine refreshes it in background
+	// (stale-while-revalidate).
+	StaleTTL time.Duration
+	// Beta factor of early probabilistic expiration (XFetch). Values are refreshed in background before
+	// becoming stale, with a probability growing as TTL elapses and proportional to the time taken to load
+	// them. One is the recommended value; zero disables early expiration.
+	Beta float64
+	// NegativeTTL time a not found result (systemerror.ErrNotFound) is cached. Zero disables negative
+	// caching.
+	NegativeTTL time.Duration
+}
+
+// IsZero indicates whether p is the zero policy, where values are stored as encoded by the Codec and loaded
+// once missing.
+func (p Policy) IsZero() bool {
+	return p == Policy{}
+}
+
+// shouldRefreshEarly runs XFetch over entry.
+func (p Policy) shouldRefreshEarly(entry policyEntry, now time.Time) bool {
+	if p.Beta <= 0 || entry.freshUntil.IsZero() || entry.delta <= 0 {
+		return false
+	}
+	gap := -float64(entry.delta) * p.Beta * math.Log(1-rand.Float64())
+	return !now.Add(time.Duration(gap)).Before(entry.freshUntil)
+}
+
+const (
+	policyEntryVersion byte = 0x01
+	policyEntryHeader       = 18
+
+	policyFlagNegative byte = 0x01
+)
+
+// policyEntry a value stored by a TypedCache with a Policy.
+//
+// Entries are encoded as: version (1 byte) | flags (1 byte) | fresh until, Unix nanoseconds (8 bytes) |
+// load duration, nanoseconds (8 bytes) | payload. Payload is the encoded value, or a JSON-encoded
+// policyNegativeEntry for not found results.
+type policyEntry struct {
+	negative   bool
+	freshUntil time.Time
+	delta      time.Duration
+	payload    []byte
+}
+
+// policyNegativeEntry a cached not found result.
+type policyNegativeEntry struct {
+	Reason   string            `json:"reason"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func (e policyEntry) isFresh(now time.Time) bool {
+	return e.freshUntil.IsZero() || now.Before(e.freshUntil)
+}
+
+func (e policyEntry) encode() []byte {
+	out := make([]byte, policyEntryHeader, policyEntryHeader+len(e.payload))
+	out[0] = policyEntryVersion
+	if e.negative {
+		out[1] |= policyFlagNegative
+	}
+	if !e.freshUntil.IsZero() {
+		binary.BigEndian.PutUint64(out[2:10], uint64(e.freshUntil.UnixNano()))
+	}
+	binary.BigEndian.PutUint64(out[10:18], uint64(e.delta))
+	return append(out, e.payload...)
+}
+
+func decodePolicyEntry(data []byte) (policyEntry, error) {
+	if len(data) < policyEntryHeader || data[0] != policyEntryVersion {
+		return policyEntry{}, ErrCacheMiss
+	}
+	entry := policyEntry{
+		negative: data[1]&policyFlagNegative != 0,
+		delta:    time.Duration(binary.BigEndian.Uint64(data[10:18])),
+		payload:  data[policyEntryHeader:],
+	}
+	if freshUntil := binary.BigEndian.Uint64(data[2:10]); freshUntil != 0 {
+		entry.freshUntil = time.Unix(0, int64(freshUntil))
+	}
+	return entry, nil
+}
+
+// isNotFoundError indicates whether err is a not found result.
+func isNotFoundError(err error) bool {
+	var sysErr systemerror.Error
+	return errors.Is(err, systemerror.ErrNotFound) ||
+		(errors.As(err, &sysErr) && sysErr.Status() == systemerror.StatusNotFound)
+}
+
+func newPolicyNegativeEntry(err error) policyNegativeEntry {
+	var sysErr systemerror.Error
+	if errors.As(err, &sysErr) {
+		return policyNegativeEntry{
+			Reason:   sysErr.Reason(),
+			Message:  sysErr.Message(),
+			Metadata: sysErr.Metadata(),
+		}
+	}
+	return policyNegativeEntry{
+		Reason:  systemerror.StatusNotFound.String(),
+		Message: err.Error(),
+	}
+}
+
+// decodeNegativeError rebuilds the not found error cached in payload.
+func decodeNegativeError(payload []byte) error {
+	negative := policyNegativeEntry{}
+	_ = json.Unmarshal(payload, &negative)
+	return systemerror.SystemError{
+		ErrStatus:   systemerror.StatusNotFound,
+		ErrReason:   negative.Reason,
+		ErrMessage:  negative.Message,
+		ErrMetadata: negative.Metadata,
+		StaticError: systemerror.ErrNotFound,
+	}
+}
@@ -0,0 +1,111 @@
+package caching_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+func newCountingLoaderTest(calls *atomic.Int32, delay time.Duration) caching.LoaderFunc[string, int32] {
+	return func(_ context.Context, _ string) (int32, error) {
+		time.Sleep(delay)
+		return calls.Add(1), nil
+	}
+}
+
+func TestTypedCache_StaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	typed := caching.NewTypedCache[string, int32](newCacheEmbeddedTest(t), caching.CodecJSON{}, "counter:")
+	typed.Policy = caching.Policy{TTL: 50 * time.Millisecond, StaleTTL: time.Minute}
+	calls := atomic.Int32{}
+	loader := newCountingLoaderTest(&calls, 50*time.Millisecond)
+
+	got, err := typed.GetOrLoad(ctx, "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), got)
+	time.Sleep(60 * time.Millisecond)
+
+	// stale value is served to every caller while a single refresh runs
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stale, errLoad := typed.GetOrLoad(ctx, "a", loader)
+			assert.NoError(t, errLoad)
+			assert.Equal(t, int32(1), stale)
+		}()
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool {
+		refreshed, _ := typed.GetOrLoad(ctx, "a", loader)
+		return refreshed == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+
+	got, err = typed.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), got)
+}
+
+func TestTypedCache_EarlyExpiration(t *testing.T) {
+	ctx := context.Background()
+	typed := caching.NewTypedCache[string, int32](newCacheEmbeddedTest(t), caching.CodecJSON{}, "counter:")
+	calls := atomic.Int32{}
+	loader := newCountingLoaderTest(&calls, 5*time.Millisecond)
+
+	typed.Policy = caching.Policy{TTL: time.Hour}
+	_, err := typed.GetOrLoad(ctx, "a", loader)
+	require.NoError(t, err)
+	got, err := typed.GetOrLoad(ctx, "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), got)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// a large beta always expires early values taking time to load
+	typed.Policy.Beta = 1e9
+	got, err = typed.GetOrLoad(ctx, "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), got)
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestTypedCache_NegativeCaching(t *testing.T) {
+	ctx := context.Background()
+	typed := caching.NewTypedCache[string, userTest](newCacheEmbeddedTest(t), caching.CodecJSON{}, "user:")
+	typed.Policy = caching.Policy{NegativeTTL: 50 * time.Millisecond}
+	calls := atomic.Int32{}
+	loader := func(_ context.Context, key string) (userTest, error) {
+		calls.Add(1)
+		return userTest{}, systemerror.NewResourceNotFound[userTest](key)
+	}
+
+	_, err := typed.GetOrLoad(ctx, "1", loader)
+	assert.ErrorIs(t, err, systemerror.ErrNotFound)
+	_, err = typed.GetOrLoad(ctx, "1", loader)
+	assert.ErrorIs(t, err, systemerror.ErrNotFound)
+	sysErr, ok := err.(systemerror.SystemError)
+	require.True(t, ok)
+	assert.Equal(t, systemerror.StatusNotFound, sysErr.Status())
+	assert.Equal(t, systemerror.NewResourceNotFound[userTest]("1").Message(), sysErr.Message())
+	assert.Equal(t, int32(1), calls.Load())
+
+	_, err = typed.Get(ctx, "1")
+	assert.ErrorIs(t, err, caching.ErrCacheMiss)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = typed.GetOrLoad(ctx, "1", loader)
+	assert.ErrorIs(t, err, systemerror.ErrNotFound)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,33 @@
+package caching
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// NewRedisClient allocates a redis.UniversalClient driven by RedisConfig. The connection is verified on
+// application start and closed on application stop.
+func NewRedisClient(lifecycle fx.Lifecycle, cfg RedisConfig) redis.UniversalClient {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:        cfg.Addresses,
+		MasterName:   cfg.MasterName,
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		PoolSize:     cfg.PoolSize,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	})
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+		OnStop: func(_ context.Context) error {
+			return client.Close()
+		},
+	})
+	return client
+}
@@ -1,8 +1,28 @@
 package data
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/neutrinocorp/geck/systemerror"
+)
 
 var (
 	// ErrInvalidPageToken the token cannot be built.
 	ErrInvalidPageToken = errors.New("invalid page token")
+	// ErrPageTokenCodecNotInitialized the PageTokenCodec was not allocated by NewPageTokenCodec, so it holds no
+	// secret key.
+	ErrPageTokenCodecNotInitialized = errors.New("page token codec not initialized")
 )
+
+// NewInvalidPageToken allocates a systemerror.SystemError with systemerror.StatusInvalidArgument and
+// ErrInvalidPageToken.
+//
+// The given PageToken is malformed, expired or was issued by another query.
+func NewInvalidPageToken(reason string) systemerror.SystemError {
+	return systemerror.SystemError{
+		ErrStatus:   systemerror.StatusInvalidArgument,
+		ErrReason:   reason,
+		ErrMessage:  "page token is not valid",
+		StaticError: ErrInvalidPageToken,
+	}
+}
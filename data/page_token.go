@@ -34,6 +34,8 @@ var _ fmt.Stringer = PageToken{}
 var _ encoding.TextMarshaler = PageToken{}
 
 // NewPageToken allocates a new PageToken instance.
+//
+// Deprecated: tokens are not authenticated nor expire. Use PageTokenCodec instead.
 func NewPageToken(encryptor encryption.Encryptor, queryType PaginationType, value string) (PageToken, error) {
 	rawValue := string(queryType) + pageTokenSeparator + value
 	ciphertext, err := encryptor.Encrypt(rawValue)
@@ -46,10 +48,12 @@ func NewPageToken(encryptor encryption.Encryptor, queryType PaginationType, valu
 }
 
 // Read decomposes encrypted token to a set of PaginationType and its value.
+//
+// Deprecated: tokens are not authenticated nor expire. Use PageTokenCodec instead.
 func (p PageToken) Read(encryptor encryption.Encryptor) (string, string, error) {
 	ciphertextBytes := make([]byte, hex.DecodedLen(len(p)))
 	if _, err := hex.Decode(ciphertextBytes, p); err != nil {
-		return "", "", nil
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
 	}
 
 	decryptedToken, err := encryptor.Decrypt(ciphertextBytes)
@@ -59,7 +63,7 @@ func (p PageToken) Read(encryptor encryption.Encryptor) (string, string, error)
 
 	splitToken := strings.SplitN(string(decryptedToken), pageTokenSeparator, 2)
 	if len(splitToken) != 2 {
-		return "", "", ErrInvalidPageToken
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
 	}
 	return splitToken[0], splitToken[1], nil
 }
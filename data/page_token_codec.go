@@ -0,0 +1,130 @@
+package data
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"strings"
+	"time"
+)
+
+// PageTokenVersion2 version byte of PageToken instances issued by PageTokenCodec.
+const PageTokenVersion2 byte = 2
+
+// ConfigPageToken configuration structure for PageTokenCodec.
+type ConfigPageToken struct {
+	// SecretKey AES key used to seal tokens. Must be 16, 24 or 32 bytes long and kept secret, as anyone
+	// holding it is able to forge tokens.
+	SecretKey string `env:"PAGE_TOKEN_SECRET_KEY,required,unset"`
+	// TTL time a token remains valid after being issued. Tokens never expire if zero.
+	TTL time.Duration `env:"PAGE_TOKEN_TTL" envDefault:"1h"`
+}
+
+// PageTokenCodec issues and reads PageToken instances using authenticated encryption (AES-GCM).
+//
+// Tokens carry the time they were issued and a hash of the query that produced them, so they expire and
+// cannot be reused by other queries. Token layout before hex encoding:
+//
+//	VERSION (1) | NONCE (12) | SEALED(ISSUED_AT (8) | QUERY_HASH (8) | QUERY_TYPE#VALUE)
+//
+// The version byte is authenticated as additional data.
+type PageTokenCodec struct {
+	TTL time.Duration
+	// Now retrieves current time, used for token expiration.
+	Now func() time.Time
+
+	aead cipher.AEAD
+}
+
+// NewPageTokenCodec allocates a PageTokenCodec instance.
+func NewPageTokenCodec(cfg ConfigPageToken) (PageTokenCodec, error) {
+	block, err := aes.NewCipher([]byte(cfg.SecretKey))
+	if err != nil {
+		return PageTokenCodec{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return PageTokenCodec{}, err
+	}
+	return PageTokenCodec{
+		TTL:  cfg.TTL,
+		Now:  time.Now,
+		aead: aead,
+	}, nil
+}
+
+func (c PageTokenCodec) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// Encode issues a PageToken for the given query type and value, bound to queryHash.
+//
+// Returns ErrPageTokenCodecNotInitialized if c was not allocated by NewPageTokenCodec.
+func (c PageTokenCodec) Encode(queryType PaginationType, value string, queryHash uint64) (PageToken, error) {
+	if c.aead == nil {
+		return nil, ErrPageTokenCodecNotInitialized
+	}
+	payload := make([]byte, 16, 16+len(queryType)+len(pageTokenSeparator)+len(value))
+	binary.BigEndian.PutUint64(payload[:8], uint64(c.now().Unix()))
+	binary.BigEndian.PutUint64(payload[8:16], queryHash)
+	payload = append(payload, queryType...)
+	payload = append(payload, pageTokenSeparator...)
+	payload = append(payload, value...)
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	// nonce and additional data must not overlap the sealed output (cipher.AEAD contract)
+	additionalData := []byte{PageTokenVersion2}
+	raw := make([]byte, 0, len(additionalData)+len(nonce)+len(payload)+c.aead.Overhead())
+	raw = append(raw, additionalData...)
+	raw = append(raw, nonce...)
+	raw = c.aead.Seal(raw, nonce, payload, additionalData)
+
+	token := make([]byte, hex.EncodedLen(len(raw)))
+	hex.Encode(token, raw)
+	return token, nil
+}
+
+// Decode verifies token and decomposes it into its PaginationType and value.
+//
+// Returns an error wrapping ErrInvalidPageToken if token is malformed, was tampered, expired or was not
+// issued for queryHash. Returns ErrPageTokenCodecNotInitialized if c was not allocated by NewPageTokenCodec.
+func (c PageTokenCodec) Decode(token PageToken, queryHash uint64) (PaginationType, string, error) {
+	if c.aead == nil {
+		return "", "", ErrPageTokenCodecNotInitialized
+	}
+	raw := make([]byte, hex.DecodedLen(len(token)))
+	if _, err := hex.Decode(raw, token); err != nil {
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(raw) < 1+nonceSize || raw[0] != PageTokenVersion2 {
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
+	}
+
+	payload, err := c.aead.Open(nil, raw[1:1+nonceSize], raw[1+nonceSize:], raw[:1])
+	if err != nil || len(payload) < 16 {
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
+	}
+
+	issuedAt := time.Unix(int64(binary.BigEndian.Uint64(payload[:8])), 0)
+	if c.TTL > 0 && c.now().After(issuedAt.Add(c.TTL)) {
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_EXPIRED")
+	} else if binary.BigEndian.Uint64(payload[8:16]) != queryHash {
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_QUERY_MISMATCH")
+	}
+
+	queryType, value, ok := strings.Cut(string(payload[16:]), pageTokenSeparator)
+	if !ok {
+		return "", "", NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
+	}
+	return PaginationType(queryType), value, nil
+}
@@ -0,0 +1,70 @@
+package data_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/security/encryption"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+func TestPageTokenCodec(t *testing.T) {
+	codec, err := data.NewPageTokenCodec(data.ConfigPageToken{
+		SecretKey: data.PageTokenDefaultEncryptionKey,
+		TTL:       time.Minute,
+	})
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	codec.Now = func() time.Time {
+		return now
+	}
+
+	token, err := codec.Encode(data.PaginationTypeOffset, "100", 42)
+	require.NoError(t, err)
+	queryType, value, err := codec.Decode(token, 42)
+	require.NoError(t, err)
+	assert.Equal(t, data.PaginationTypeOffset, queryType)
+	assert.Equal(t, "100", value)
+
+	_, _, err = codec.Decode(token, 43)
+	assert.ErrorIs(t, err, data.ErrInvalidPageToken)
+	var sysErr systemerror.Error
+	require.ErrorAs(t, err, &sysErr)
+	assert.Equal(t, systemerror.StatusInvalidArgument, sysErr.Status())
+	assert.Equal(t, "PAGE_TOKEN_QUERY_MISMATCH", sysErr.Reason())
+
+	v1Token, err := data.NewPageToken(encryption.NewEncryptorAES(encryption.ConfigEncryptor{
+		SecretKey: data.PageTokenDefaultEncryptionKey,
+	}), data.PaginationTypeOffset, "100")
+	require.NoError(t, err)
+	_, _, err = codec.Decode(v1Token, 42)
+	assert.ErrorIs(t, err, data.ErrInvalidPageToken)
+
+	now = now.Add(2 * time.Minute)
+	_, _, err = codec.Decode(token, 42)
+	require.ErrorAs(t, err, &sysErr)
+	assert.Equal(t, "PAGE_TOKEN_EXPIRED", sysErr.Reason())
+
+	_, err = data.PageTokenCodec{}.Encode(data.PaginationTypeOffset, "100", 42)
+	assert.ErrorIs(t, err, data.ErrPageTokenCodecNotInitialized)
+	_, _, err = data.PageTokenCodec{}.Decode(token, 42)
+	assert.ErrorIs(t, err, data.ErrPageTokenCodecNotInitialized)
+}
+
+func TestConfigPageToken(t *testing.T) {
+	_, err := env.ParseAsWithOptions[data.ConfigPageToken](env.Options{Environment: map[string]string{}})
+	assert.Error(t, err, "secret key must be required")
+}
+
+func TestPageToken_Read(t *testing.T) {
+	encryptor := encryption.NewEncryptorAES(encryption.ConfigEncryptor{
+		SecretKey: data.PageTokenDefaultEncryptionKey,
+	})
+	_, _, err := data.PageToken("not hex").Read(encryptor)
+	assert.ErrorIs(t, err, data.ErrInvalidPageToken)
+}
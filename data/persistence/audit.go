@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/neutrinocorp/geck/security"
+)
+
+// Clock provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock implementation using system time in UTC.
+type SystemClock struct{}
+
+var _ Clock = SystemClock{}
+
+func (s SystemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// AuditMetadata audit information of a persisted entity: who and when created, updated and (soft) deleted it.
+//
+// Fields are tagged with DefaultAuditColumnsSQL names, so they can be used in Criteria by RepositoryMemory.
+type AuditMetadata struct {
+	CreatedAt time.Time `persistence:"created_at"`
+	CreatedBy string    `persistence:"created_by"`
+	UpdatedAt time.Time `persistence:"updated_at"`
+	UpdatedBy string    `persistence:"updated_by"`
+	// DeletedAt soft deletion time. Nil if entity is not deleted.
+	DeletedAt *time.Time `persistence:"deleted_at"`
+	DeletedBy string     `persistence:"deleted_by"`
+}
+
+var _ SoftDeletable = AuditMetadata{}
+
+// IsDeleted indicates whether the entity was soft deleted.
+func (a AuditMetadata) IsDeleted() bool {
+	return a.DeletedAt != nil
+}
+
+// Auditor populates AuditMetadata using the security.Principal found in context.Context and a Clock.
+type Auditor struct {
+	Clock Clock
+	// DefaultActor actor used when no security.Principal is found (e.g. background jobs).
+	DefaultActor string
+}
+
+// NewAuditor allocates an Auditor instance using SystemClock and "system" as default actor.
+func NewAuditor() Auditor {
+	return Auditor{
+		Clock:        SystemClock{},
+		DefaultActor: "system",
+	}
+}
+
+// Actor returns the security.Principal identifier found in ctx. Returns Auditor.DefaultActor otherwise.
+func (a Auditor) Actor(ctx context.Context) string {
+	principal, err := security.GetPrincipalFromContext(ctx)
+	if err != nil || principal.ID() == "" {
+		return a.DefaultActor
+	}
+	return principal.ID()
+}
+
+// NewCreated allocates AuditMetadata for a newly created entity.
+func (a Auditor) NewCreated(ctx context.Context) AuditMetadata {
+	now := a.Clock.Now()
+	actor := a.Actor(ctx)
+	return AuditMetadata{
+		CreatedAt: now,
+		CreatedBy: actor,
+		UpdatedAt: now,
+		UpdatedBy: actor,
+	}
+}
+
+// Updated returns a copy of src marked as updated.
+func (a Auditor) Updated(ctx context.Context, src AuditMetadata) AuditMetadata {
+	src.UpdatedAt = a.Clock.Now()
+	src.UpdatedBy = a.Actor(ctx)
+	return src
+}
+
+// Deleted returns a copy of src marked as (soft) deleted.
+func (a Auditor) Deleted(ctx context.Context, src AuditMetadata) AuditMetadata {
+	src = a.Updated(ctx, src)
+	deletedAt := src.UpdatedAt
+	src.DeletedAt = &deletedAt
+	src.DeletedBy = src.UpdatedBy
+	return src
+}
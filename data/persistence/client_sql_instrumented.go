@@ -0,0 +1,123 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"time"
+
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/observability/tracing"
+)
+
+// InstrumentedClientSQL is a ClientSQL decorator recording StatementStatsSQL (latency, rows affected, errors
+// by systemerror.Status) of every operation into a MetricsRecorderSQL.
+//
+// Statements taking longer than ConfigInstrumentedClientSQL.SlowQueryThreshold are logged as warnings.
+//
+// QueryContext and QueryRowContext record statements once the driver returns, before rows are read: the
+// recorded duration excludes the time spent iterating rows, and errors reported by sql.Rows.Err or
+// sql.Row.Scan (e.g. sql.ErrNoRows, connection drops while streaming) are not recorded. sql.Rows and
+// sql.Row are concrete types, hence they cannot be decorated to record on close.
+type InstrumentedClientSQL struct {
+	Config   ConfigInstrumentedClientSQL
+	Logger   logging.Logger
+	Recorder MetricsRecorderSQL
+	Next     ClientSQL
+}
+
+var _ ClientSQL = (*InstrumentedClientSQL)(nil)
+
+func NewInstrumentedClientSQL(cfg ConfigInstrumentedClientSQL, logger logging.Logger, recorder MetricsRecorderSQL,
+	next ClientSQL) InstrumentedClientSQL {
+	return InstrumentedClientSQL{
+		Config:   cfg,
+		Logger:   logger,
+		Recorder: recorder,
+		Next:     next,
+	}
+}
+
+func (c InstrumentedClientSQL) record(ctx context.Context, operation StatementOperationSQL, query string,
+	start time.Time, rowsAffected int64, err error) {
+	stats := StatementStatsSQL{
+		Operation:    operation,
+		Query:        query,
+		Duration:     time.Since(start),
+		RowsAffected: rowsAffected,
+		Err:          err,
+		Status:       NewStatusFromErrorSQL(err),
+	}
+	stats.TraceID, _ = tracing.GetTraceIDFromContext(ctx)
+	if c.Config.NormalizeQueries && query != "" {
+		stats.Query = NormalizeQuerySQL(query)
+		stats.Fingerprint = NewQueryFingerprintSQL(query)
+	}
+	if c.Recorder != nil {
+		c.Recorder.RecordStatement(ctx, stats)
+	}
+
+	if c.Config.SlowQueryThreshold > 0 && stats.Duration >= c.Config.SlowQueryThreshold {
+		c.Logger.Warn().
+			WithField("operation", string(stats.Operation)).
+			WithField("statement", stats.Query).
+			WithField("fingerprint", stats.Fingerprint).
+			WithField("duration", stats.Duration).
+			WithField("threshold", c.Config.SlowQueryThreshold).
+			WriteWithCtx(ctx, "slow statement detected")
+	}
+}
+
+func (c InstrumentedClientSQL) PingContext(ctx context.Context) error {
+	start := time.Now()
+	err := c.Next.PingContext(ctx)
+	c.record(ctx, StatementOperationPing, "", start, -1, err)
+	return err
+}
+
+func (c InstrumentedClientSQL) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := c.Next.ExecContext(ctx, query, args...)
+	var rowsAffected int64 = -1
+	if err == nil {
+		if affected, errRows := res.RowsAffected(); errRows == nil {
+			rowsAffected = affected
+		}
+	}
+	c.record(ctx, StatementOperationExec, query, start, rowsAffected, err)
+	return res, err
+}
+
+func (c InstrumentedClientSQL) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	start := time.Now()
+	stmt, err := c.Next.PrepareContext(ctx, query)
+	c.record(ctx, StatementOperationPrepare, query, start, -1, err)
+	return stmt, err
+}
+
+// QueryContext executes the query, recording its outcome before the returned rows are read.
+func (c InstrumentedClientSQL) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := c.Next.QueryContext(ctx, query, args...)
+	c.record(ctx, StatementOperationQuery, query, start, -1, err)
+	return rows, err
+}
+
+// QueryRowContext executes the query, recording its outcome before the returned row is scanned.
+func (c InstrumentedClientSQL) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := c.Next.QueryRowContext(ctx, query, args...)
+	c.record(ctx, StatementOperationQueryRow, query, start, -1, row.Err())
+	return row
+}
+
+func (c InstrumentedClientSQL) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	start := time.Now()
+	tx, err := c.Next.BeginTx(ctx, opts)
+	c.record(ctx, StatementOperationBeginTx, "", start, -1, err)
+	return tx, err
+}
+
+func (c InstrumentedClientSQL) Driver() driver.Driver {
+	return c.Next.Driver()
+}
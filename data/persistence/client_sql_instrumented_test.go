@@ -0,0 +1,90 @@
+package persistence_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/observability/tracing"
+)
+
+func TestNormalizeQuerySQL(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{
+			in:  "SELECT * FROM users   WHERE id = 10 AND name = 'O''Brien'",
+			exp: "SELECT * FROM users WHERE id = ? AND name = ?",
+		},
+		{
+			in:  "SELECT id FROM users WHERE id IN ($1, $2, $3) AND created_at > :since::timestamp",
+			exp: "SELECT id FROM users WHERE id IN (?) AND created_at > ?::timestamp",
+		},
+		{
+			in:  "UPDATE table_2 SET score = 99.5 WHERE id = ?",
+			exp: "UPDATE table_2 SET score = ? WHERE id = ?",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.exp, persistence.NormalizeQuerySQL(tt.in))
+		})
+	}
+	assert.Equal(t, persistence.NewQueryFingerprintSQL("SELECT 1 FROM foo WHERE id = 1"),
+		persistence.NewQueryFingerprintSQL("SELECT 1 FROM foo WHERE id = 2"))
+}
+
+type recorderStub struct {
+	stats []persistence.StatementStatsSQL
+}
+
+func (r *recorderStub) RecordStatement(_ context.Context, stats persistence.StatementStatsSQL) {
+	r.stats = append(r.stats, stats)
+}
+
+func TestInstrumentedClientSQL(t *testing.T) {
+	db, mock := newMockClientSQL(t)
+	buf := bytes.NewBuffer(nil)
+	recorder := &recorderStub{}
+	client := persistence.NewInstrumentedClientSQL(persistence.ConfigInstrumentedClientSQL{
+		SlowQueryThreshold: 1,
+		NormalizeQueries:   true,
+	}, logging.NewStdLogger(log.New(buf, "", 0)), recorder, db)
+	ctx := context.WithValue(context.Background(), tracing.TraceIDContextKey, "trace-123")
+
+	mock.ExpectExec("UPDATE foo").WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery("SELECT id").WillReturnError(sql.ErrConnDone)
+	_, err := client.ExecContext(ctx, "UPDATE foo SET name = 'bar' WHERE id > 10")
+	require.NoError(t, err)
+	_, err = client.QueryContext(ctx, "SELECT id FROM foo WHERE id = $1", 1)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, recorder.stats, 2)
+	assert.Equal(t, persistence.StatementOperationExec, recorder.stats[0].Operation)
+	assert.Equal(t, "UPDATE foo SET name = ? WHERE id > ?", recorder.stats[0].Query)
+	assert.NotEmpty(t, recorder.stats[0].Fingerprint)
+	assert.Equal(t, int64(3), recorder.stats[0].RowsAffected)
+	assert.Equal(t, "trace-123", recorder.stats[0].TraceID)
+	assert.Zero(t, recorder.stats[0].Status)
+	assert.Equal(t, "UNAVAILABLE", recorder.stats[1].Status.String())
+	assert.Contains(t, buf.String(), "slow statement detected")
+	assert.Contains(t, buf.String(), "trace-123")
+
+	metrics := persistence.NewMetricsSQL()
+	for _, stats := range recorder.stats {
+		metrics.RecordStatement(ctx, stats)
+	}
+	snapshot := metrics.Snapshot()
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, uint64(1), snapshot[1].Errors["UNAVAILABLE"])
+}
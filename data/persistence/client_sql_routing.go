@@ -0,0 +1,231 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"time"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+// ReplicaBalancingStrategy the algorithm used by RoutingClientSQL to pick a read replica.
+type ReplicaBalancingStrategy string
+
+const (
+	// ReplicaBalancingRoundRobin distributes read statements evenly across healthy replicas.
+	ReplicaBalancingRoundRobin ReplicaBalancingStrategy = "ROUND_ROBIN"
+	// ReplicaBalancingLeastLatency sends read statements to the healthy replica with the lowest
+	// observed latency (exponentially weighted moving average).
+	ReplicaBalancingLeastLatency ReplicaBalancingStrategy = "LEAST_LATENCY"
+)
+
+// latencyDecayFactor weight of the most recent latency sample in the moving average.
+const latencyDecayFactor = 0.2
+
+type readYourWritesContextType string
+
+const readYourWritesContextKey readYourWritesContextType = "persistence.read_your_writes"
+
+// NewReadYourWritesContext marks the given context.Context, so RoutingClientSQL sends read statements to the
+// primary database. Use it when a read must observe a previous write not yet replicated.
+func NewReadYourWritesContext(parent context.Context) context.Context {
+	return context.WithValue(parent, readYourWritesContextKey, true)
+}
+
+// IsReadYourWrites indicates whether the given context.Context was marked with NewReadYourWritesContext.
+func IsReadYourWrites(ctx context.Context) bool {
+	isMarked, _ := ctx.Value(readYourWritesContextKey).(bool)
+	return isMarked
+}
+
+type replicaSQL struct {
+	client   ClientSQL
+	actuator ActuatorSQL
+	healthy  atomic.Bool
+	// latency moving average in nanoseconds.
+	latency atomic.Int64
+}
+
+func (r *replicaSQL) observeLatency(d time.Duration) {
+	for {
+		prev := r.latency.Load()
+		next := int64(d)
+		if prev > 0 {
+			next = int64(latencyDecayFactor*float64(d) + (1-latencyDecayFactor)*float64(prev))
+		}
+		if r.latency.CompareAndSwap(prev, next) {
+			return
+		}
+	}
+}
+
+// RoutingClientSQL is a ClientSQL routing read statements (QueryContext, QueryRowContext) to a pool of read
+// replicas and every other operation to the primary database.
+//
+// Read statements are sent to the primary if the context.Context holds a Transaction or was marked with
+// NewReadYourWritesContext, or if no healthy replica is available.
+//
+// Replicas are ejected from (and re-admitted to) the pool by CheckReplicas based on their ActuatorSQL state.
+type RoutingClientSQL struct {
+	Config   ConfigRoutingClientSQL
+	Logger   logging.Logger
+	Primary  ClientSQL
+	replicas []*replicaSQL
+	counter  atomic.Uint64
+}
+
+var _ ClientSQL = (*RoutingClientSQL)(nil)
+
+// NewRoutingClientSQL allocates a RoutingClientSQL instance. Replicas are considered healthy until
+// CheckReplicas says otherwise.
+func NewRoutingClientSQL(cfg ConfigRoutingClientSQL, logger logging.Logger, primary ClientSQL,
+	replicas ...ClientSQL) *RoutingClientSQL {
+	r := &RoutingClientSQL{
+		Config:   cfg,
+		Logger:   logger,
+		Primary:  primary,
+		replicas: make([]*replicaSQL, 0, len(replicas)),
+	}
+	for _, client := range replicas {
+		replica := &replicaSQL{
+			client:   client,
+			actuator: NewActuatorSQL(client),
+		}
+		replica.healthy.Store(true)
+		r.replicas = append(r.replicas, replica)
+	}
+	return r
+}
+
+// CheckReplicas fetches the ActuatorSQL state of every replica. Replicas not in actuator.StatusUp are ejected
+// from the pool while healthy ones are (re-)admitted. Each check is bounded by
+// ConfigRoutingClientSQL.HealthCheckTimeout.
+func (r *RoutingClientSQL) CheckReplicas(ctx context.Context) {
+	for i, replica := range r.replicas {
+		start := time.Now()
+		state, err := r.checkReplica(ctx, replica)
+		isHealthy := err == nil && state.Status == actuator.StatusUp
+		if isHealthy {
+			replica.observeLatency(time.Since(start))
+		}
+		if wasHealthy := replica.healthy.Swap(isHealthy); wasHealthy == isHealthy {
+			continue
+		}
+
+		if isHealthy {
+			r.Logger.Info().WithField("replica", i).WriteWithCtx(ctx, "read replica admitted")
+			continue
+		}
+		r.Logger.Warn().
+			WithField("replica", i).
+			WithField("description", state.Description).
+			WriteWithCtx(ctx, "read replica ejected")
+	}
+}
+
+// MonitorReplicas runs CheckReplicas every ConfigRoutingClientSQL.HealthCheckInterval until ctx is done.
+func (r *RoutingClientSQL) MonitorReplicas(ctx context.Context) {
+	if r.Config.HealthCheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(r.Config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckReplicas(ctx)
+		}
+	}
+}
+
+func (r *RoutingClientSQL) checkReplica(ctx context.Context, replica *replicaSQL) (actuator.State, error) {
+	if r.Config.HealthCheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Config.HealthCheckTimeout)
+		defer cancel()
+	}
+	return replica.actuator.State(ctx)
+}
+
+func (r *RoutingClientSQL) pickReplica(ctx context.Context) *replicaSQL {
+	if IsReadYourWrites(ctx) {
+		return nil
+	} else if _, err := GetTxFromContext(ctx); err == nil {
+		return nil
+	}
+
+	switch r.Config.BalancingStrategy {
+	case ReplicaBalancingLeastLatency:
+		var selected *replicaSQL
+		for _, replica := range r.replicas {
+			if !replica.healthy.Load() {
+				continue
+			}
+			if selected == nil || replica.latency.Load() < selected.latency.Load() {
+				selected = replica
+			}
+		}
+		return selected
+	default:
+		total := uint64(len(r.replicas))
+		start := r.counter.Add(1)
+		for i := uint64(0); i < total; i++ {
+			replica := r.replicas[(start+i)%total]
+			if replica.healthy.Load() {
+				return replica
+			}
+		}
+		return nil
+	}
+}
+
+func (r *RoutingClientSQL) PingContext(ctx context.Context) error {
+	return r.Primary.PingContext(ctx)
+}
+
+func (r *RoutingClientSQL) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.Primary.ExecContext(ctx, query, args...)
+}
+
+func (r *RoutingClientSQL) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return r.Primary.PrepareContext(ctx, query)
+}
+
+func (r *RoutingClientSQL) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	replica := r.pickReplica(ctx)
+	if replica == nil {
+		return r.Primary.QueryContext(ctx, query, args...)
+	}
+	start := time.Now()
+	rows, err := replica.client.QueryContext(ctx, query, args...)
+	if err == nil {
+		replica.observeLatency(time.Since(start))
+	}
+	return rows, err
+}
+
+func (r *RoutingClientSQL) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	replica := r.pickReplica(ctx)
+	if replica == nil {
+		return r.Primary.QueryRowContext(ctx, query, args...)
+	}
+	start := time.Now()
+	row := replica.client.QueryRowContext(ctx, query, args...)
+	if row.Err() == nil {
+		replica.observeLatency(time.Since(start))
+	}
+	return row
+}
+
+func (r *RoutingClientSQL) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return r.Primary.BeginTx(ctx, opts)
+}
+
+func (r *RoutingClientSQL) Driver() driver.Driver {
+	return r.Primary.Driver()
+}
@@ -0,0 +1,107 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+func newMockClientSQL(t *testing.T) (persistence.ClientSQL, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return db, mock
+}
+
+func TestRoutingClientSQL(t *testing.T) {
+	logger := logging.NewStdLogger(log.New(io.Discard, "", 0))
+	primary, primaryMock := newMockClientSQL(t)
+	replicaA, replicaAMock := newMockClientSQL(t)
+	replicaB, replicaBMock := newMockClientSQL(t)
+	client := persistence.NewRoutingClientSQL(persistence.ConfigRoutingClientSQL{
+		BalancingStrategy: persistence.ReplicaBalancingRoundRobin,
+	}, logger, primary, replicaA, replicaB)
+	ctx := context.Background()
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id"}).AddRow(1)
+	}
+
+	// reads are balanced across replicas
+	replicaBMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	replicaAMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	var id int
+	require.NoError(t, client.QueryRowContext(ctx, "SELECT id FROM foo").Scan(&id))
+	require.NoError(t, client.QueryRowContext(ctx, "SELECT id FROM foo").Scan(&id))
+
+	// writes and read-your-writes reads go to primary
+	primaryMock.ExpectExec("UPDATE foo").WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	_, err := client.ExecContext(ctx, "UPDATE foo SET id = 2")
+	require.NoError(t, err)
+	require.NoError(t, client.QueryRowContext(persistence.NewReadYourWritesContext(ctx), "SELECT id FROM foo").Scan(&id))
+
+	// reads within transactions go to primary
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	primaryMock.ExpectCommit()
+	factory := persistence.NewTransactionContextFactorySQLWithLogger(primary, persistence.ConfigTransactionManagerSQL{}, logger)
+	txCtx, err := factory.NewContext(ctx)
+	require.NoError(t, err)
+	txClient := persistence.NewRoutingClientSQL(client.Config, logger,
+		persistence.NewTransactionalClientSQL(factory, logger, primary), replicaA, replicaB)
+	require.NoError(t, txClient.QueryRowContext(txCtx, "SELECT id FROM foo").Scan(&id))
+	require.NoError(t, persistence.CloseTransaction(txCtx, nil))
+
+	// unhealthy replicas are ejected
+	replicaAMock.ExpectQuery("SELECT version").WillReturnError(errors.New("connection refused"))
+	replicaBMock.ExpectQuery("SELECT version").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("16"))
+	client.CheckReplicas(ctx)
+	replicaBMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	replicaBMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	require.NoError(t, client.QueryRowContext(ctx, "SELECT id FROM foo").Scan(&id))
+	require.NoError(t, client.QueryRowContext(ctx, "SELECT id FROM foo").Scan(&id))
+
+	// primary is used if no replica is healthy
+	replicaAMock.ExpectQuery("SELECT version").WillReturnError(errors.New("connection refused"))
+	replicaBMock.ExpectQuery("SELECT version").WillReturnError(errors.New("connection refused"))
+	client.CheckReplicas(ctx)
+	primaryMock.ExpectQuery("SELECT id").WillReturnRows(rows())
+	require.NoError(t, client.QueryRowContext(ctx, "SELECT id FROM foo").Scan(&id))
+
+	for _, mock := range []sqlmock.Sqlmock{primaryMock, replicaAMock, replicaBMock} {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRoutingClientSQL_CheckReplicasTimeout(t *testing.T) {
+	logger := logging.NewStdLogger(log.New(io.Discard, "", 0))
+	primary, primaryMock := newMockClientSQL(t)
+	replica, replicaMock := newMockClientSQL(t)
+	client := persistence.NewRoutingClientSQL(persistence.ConfigRoutingClientSQL{
+		HealthCheckTimeout: 20 * time.Millisecond,
+	}, logger, primary, replica)
+
+	// hung replicas are ejected once the timeout elapses
+	replicaMock.ExpectQuery("SELECT version").WillDelayFor(time.Minute).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("16"))
+	start := time.Now()
+	client.CheckReplicas(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+
+	primaryMock.ExpectQuery("SELECT id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	var id int
+	require.NoError(t, client.QueryRowContext(context.Background(), "SELECT id FROM foo").Scan(&id))
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+}
@@ -0,0 +1,59 @@
+package persistence
+
+import "time"
+
+// ConfigClientSQL configuration structure for database/sql connection pools.
+type ConfigClientSQL struct {
+	// DriverName name of the registered database/sql driver (e.g. pgx, postgres, mysql).
+	DriverName string `env:"SQL_DRIVER_NAME,required"`
+	// DSN data source name used to open the connection pool.
+	DSN string `env:"SQL_DSN,required,unset"`
+	// MaxOpenConns maximum number of open connections to the database. Zero or less means unlimited.
+	MaxOpenConns int `env:"SQL_MAX_OPEN_CONNS" envDefault:"0"`
+	// MaxIdleConns maximum number of connections in the idle connection pool. Zero or less means no idle
+	// connections are retained.
+	MaxIdleConns int `env:"SQL_MAX_IDLE_CONNS" envDefault:"2"`
+	// ConnMaxLifetime maximum amount of time a connection may be reused. Zero means no limit.
+	ConnMaxLifetime time.Duration `env:"SQL_CONN_MAX_LIFETIME" envDefault:"0s"`
+	// ConnMaxIdleTime maximum amount of time a connection may be idle. Zero means no limit.
+	ConnMaxIdleTime time.Duration `env:"SQL_CONN_MAX_IDLE_TIME" envDefault:"0s"`
+	// PingTimeout maximum amount of time to wait for the database to answer the startup ping.
+	PingTimeout time.Duration `env:"SQL_PING_TIMEOUT" envDefault:"10s"`
+}
+
+// ConfigTransactionManagerSQL configuration structure for TransactionContextFactorySQL instances.
+type ConfigTransactionManagerSQL struct {
+	// IsolationLevel the isolation level used when starting new transactions. Accepts names
+	// (e.g. READ_COMMITTED) or sql.IsolationLevel values.
+	IsolationLevel IsolationLevelSQL `env:"SQL_TX_ISOLATION_LEVEL" envDefault:"DEFAULT"`
+	// ReadOnly indicates whether new transactions are read-only.
+	ReadOnly bool `env:"SQL_TX_READ_ONLY" envDefault:"false"`
+	// Propagation the default TransactionPropagation used by TransactionContextFactorySQL.NewContext.
+	Propagation TransactionPropagation `env:"SQL_TX_PROPAGATION" envDefault:"REQUIRED"`
+	// TenantSessionVariable PostgreSQL session variable (e.g. app.tenant_id) set to the tenant of
+	// security.GetTenantFromContext on each new transaction, used by row-level security policies.
+	// Transactions without a tenant are rejected. Disabled if empty.
+	TenantSessionVariable string `env:"SQL_TX_TENANT_SESSION_VARIABLE"`
+}
+
+// ConfigRoutingClientSQL configuration structure for RoutingClientSQL instances.
+type ConfigRoutingClientSQL struct {
+	// ReplicaDSNs data source names of the read replicas. Replicas use ConfigClientSQL pool settings.
+	ReplicaDSNs []string `env:"SQL_REPLICA_DSNS,unset"`
+	// BalancingStrategy the algorithm used to pick a read replica.
+	BalancingStrategy ReplicaBalancingStrategy `env:"SQL_REPLICA_BALANCING_STRATEGY" envDefault:"ROUND_ROBIN"`
+	// HealthCheckInterval interval between replica health checks. Zero disables health checking.
+	HealthCheckInterval time.Duration `env:"SQL_REPLICA_HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+	// HealthCheckTimeout maximum amount of time to wait for a replica to answer a health check, so a hung
+	// replica gets ejected instead of blocking the application start. Zero means no limit.
+	HealthCheckTimeout time.Duration `env:"SQL_REPLICA_HEALTH_CHECK_TIMEOUT" envDefault:"5s"`
+}
+
+// ConfigInstrumentedClientSQL configuration structure for InstrumentedClientSQL instances.
+type ConfigInstrumentedClientSQL struct {
+	// SlowQueryThreshold statements taking longer than this value are logged as warnings. Zero disables it.
+	SlowQueryThreshold time.Duration `env:"SQL_SLOW_QUERY_THRESHOLD" envDefault:"500ms"`
+	// NormalizeQueries replaces statement literals and parameters with placeholders before recording metrics,
+	// avoiding high-cardinality labels.
+	NormalizeQueries bool `env:"SQL_NORMALIZE_QUERIES" envDefault:"true"`
+}
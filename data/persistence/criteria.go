@@ -1,6 +1,11 @@
 package persistence
 
-import "github.com/neutrinocorp/geck/data"
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/neutrinocorp/geck/data"
+)
 
 // CriteriaFilter a filter operation for a Criteria specification.
 type CriteriaFilter struct {
@@ -30,6 +35,26 @@ type Criteria struct {
 	Ordering        CriteriaOrdering
 	LogicalOperator data.LogicalOperator
 	Filters         []CriteriaFilter
+	// IncludeDeleted includes soft deleted items in the dataset. Excluded by default.
+	IncludeDeleted bool
 }
 
+// CriteriaFields allowlist of fields a Criteria can use, mapping each field name to its storage
+// name (e.g. a SQL column).
 type CriteriaFields map[string]string
+
+// NewCriteriaHash computes a hash of the dataset specified by criteria (filters, logical operator, ordering
+// and soft deletion), used to bind a data.PageToken to the query which issued it. Page size and token are
+// excluded, so pages can be resized between requests.
+func NewCriteriaHash(criteria Criteria) uint64 {
+	hash := fnv.New64a()
+	_, _ = fmt.Fprintf(hash, "%d|%d|%s|%d|%t", criteria.LogicalOperator, criteria.Ordering.OrderType,
+		criteria.Ordering.Field, len(criteria.Filters), criteria.IncludeDeleted)
+	for _, filter := range criteria.Filters {
+		_, _ = fmt.Fprintf(hash, "|%s|%d|%d", filter.Field, filter.Operator, len(filter.Value))
+		for _, v := range filter.Value {
+			_, _ = fmt.Fprintf(hash, "|%T:%v", v, v)
+		}
+	}
+	return hash.Sum64()
+}
@@ -0,0 +1,295 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+// PlaceholderFormatSQL the bind parameter format used by a SQL driver.
+type PlaceholderFormatSQL uint8
+
+const (
+	// PlaceholderDollar numbered placeholders (e.g. $1, $2), used by PostgreSQL.
+	PlaceholderDollar PlaceholderFormatSQL = iota
+	// PlaceholderQuestion positional placeholders (e.g. ?), used by MySQL and SQLite.
+	PlaceholderQuestion
+)
+
+// AuditColumnsSQL column names of AuditMetadata fields.
+type AuditColumnsSQL struct {
+	CreatedAt string
+	CreatedBy string
+	UpdatedAt string
+	UpdatedBy string
+	DeletedAt string
+	DeletedBy string
+}
+
+// DefaultAuditColumnsSQL default AuditColumnsSQL using snake_case names.
+var DefaultAuditColumnsSQL = AuditColumnsSQL{
+	CreatedAt: "created_at",
+	CreatedBy: "created_by",
+	UpdatedAt: "updated_at",
+	UpdatedBy: "updated_by",
+	DeletedAt: "deleted_at",
+	DeletedBy: "deleted_by",
+}
+
+// QueryBuilderSQL translates Criteria and write operations into SQL statements for a single table.
+//
+// Audit columns are filled automatically from Auditor. If SoftDelete is enabled, deletions become updates of
+// the deleted audit columns and rows marked as deleted are excluded from every statement unless
+// Criteria.IncludeDeleted is set.
+type QueryBuilderSQL struct {
+	Table string
+	// Fields allowlist of Criteria fields mapped to their column names.
+	Fields       CriteriaFields
+	Placeholder  PlaceholderFormatSQL
+	Auditor      Auditor
+	AuditColumns AuditColumnsSQL
+	SoftDelete   bool
+}
+
+// NewQueryBuilderSQL allocates a QueryBuilderSQL instance with soft deletion enabled, using PlaceholderDollar
+// and DefaultAuditColumnsSQL.
+func NewQueryBuilderSQL(table string, fields CriteriaFields) QueryBuilderSQL {
+	return QueryBuilderSQL{
+		Table:        table,
+		Fields:       fields,
+		Placeholder:  PlaceholderDollar,
+		Auditor:      NewAuditor(),
+		AuditColumns: DefaultAuditColumnsSQL,
+		SoftDelete:   true,
+	}
+}
+
+type queryArgsSQL struct {
+	format PlaceholderFormatSQL
+	values []any
+}
+
+func (q *queryArgsSQL) add(v any) string {
+	q.values = append(q.values, v)
+	if q.format == PlaceholderQuestion {
+		return "?"
+	}
+	return "$" + strconv.Itoa(len(q.values))
+}
+
+// Select builds a SELECT statement for the given columns using criteria filters, ordering and page size.
+// Offset is the number of rows to skip, commonly obtained from an OFFSET data.PageToken.
+func (b QueryBuilderSQL) Select(columns []string, criteria Criteria, offset int64) (string, []any, error) {
+	args := &queryArgsSQL{format: b.Placeholder}
+	buf := strings.Builder{}
+	buf.WriteString("SELECT ")
+	if len(columns) == 0 {
+		buf.WriteString("*")
+	} else {
+		buf.WriteString(strings.Join(columns, ", "))
+	}
+	buf.WriteString(" FROM ")
+	buf.WriteString(b.Table)
+
+	where, err := b.where(criteria, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if where != "" {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(where)
+	}
+
+	if criteria.Ordering.Field != "" {
+		column, err := b.column(criteria.Ordering.Field)
+		if err != nil {
+			return "", nil, err
+		}
+		buf.WriteString(" ORDER BY ")
+		buf.WriteString(column)
+		if criteria.Ordering.OrderType == data.OrderTypeDescending {
+			buf.WriteString(" DESC")
+		} else {
+			buf.WriteString(" ASC")
+		}
+	}
+	if criteria.PageSize > 0 {
+		buf.WriteString(" LIMIT ")
+		buf.WriteString(args.add(criteria.PageSize))
+	}
+	if offset > 0 {
+		buf.WriteString(" OFFSET ")
+		buf.WriteString(args.add(offset))
+	}
+	return buf.String(), args.values, nil
+}
+
+// Insert builds an INSERT statement for the given column values, filling created and updated audit columns.
+func (b QueryBuilderSQL) Insert(ctx context.Context, values map[string]any) (string, []any) {
+	audit := b.Auditor.NewCreated(ctx)
+	row := make(map[string]any, len(values)+4)
+	for k, v := range values {
+		row[k] = v
+	}
+	row[b.AuditColumns.CreatedAt] = audit.CreatedAt
+	row[b.AuditColumns.CreatedBy] = audit.CreatedBy
+	row[b.AuditColumns.UpdatedAt] = audit.UpdatedAt
+	row[b.AuditColumns.UpdatedBy] = audit.UpdatedBy
+
+	columns := sortedKeys(row)
+	args := &queryArgsSQL{format: b.Placeholder}
+	placeholders := make([]string, 0, len(columns))
+	for _, column := range columns {
+		placeholders = append(placeholders, args.add(row[column]))
+	}
+	return "INSERT INTO " + b.Table + " (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.Join(placeholders, ", ") + ")", args.values
+}
+
+// Update builds an UPDATE statement for the row identified by idColumn, filling updated audit columns.
+// Soft deleted rows are never updated.
+func (b QueryBuilderSQL) Update(ctx context.Context, idColumn string, id any,
+	values map[string]any) (string, []any) {
+	audit := b.Auditor.Updated(ctx, AuditMetadata{})
+	row := make(map[string]any, len(values)+2)
+	for k, v := range values {
+		row[k] = v
+	}
+	row[b.AuditColumns.UpdatedAt] = audit.UpdatedAt
+	row[b.AuditColumns.UpdatedBy] = audit.UpdatedBy
+	return b.update(row, idColumn, id)
+}
+
+// Delete builds a statement removing the row identified by idColumn. If SoftDelete is enabled, the row is
+// marked as deleted instead.
+func (b QueryBuilderSQL) Delete(ctx context.Context, idColumn string, id any) (string, []any) {
+	if !b.SoftDelete {
+		args := &queryArgsSQL{format: b.Placeholder}
+		return "DELETE FROM " + b.Table + " WHERE " + idColumn + " = " + args.add(id), args.values
+	}
+
+	audit := b.Auditor.Deleted(ctx, AuditMetadata{})
+	return b.update(map[string]any{
+		b.AuditColumns.UpdatedAt: audit.UpdatedAt,
+		b.AuditColumns.UpdatedBy: audit.UpdatedBy,
+		b.AuditColumns.DeletedAt: *audit.DeletedAt,
+		b.AuditColumns.DeletedBy: audit.DeletedBy,
+	}, idColumn, id)
+}
+
+func (b QueryBuilderSQL) update(row map[string]any, idColumn string, id any) (string, []any) {
+	columns := sortedKeys(row)
+	args := &queryArgsSQL{format: b.Placeholder}
+	assignments := make([]string, 0, len(columns))
+	for _, column := range columns {
+		assignments = append(assignments, column+" = "+args.add(row[column]))
+	}
+	query := "UPDATE " + b.Table + " SET " + strings.Join(assignments, ", ") +
+		" WHERE " + idColumn + " = " + args.add(id)
+	if b.SoftDelete {
+		query += " AND " + b.AuditColumns.DeletedAt + " IS NULL"
+	}
+	return query, args.values
+}
+
+func (b QueryBuilderSQL) column(field string) (string, error) {
+	column, ok := b.Fields[field]
+	if !ok {
+		return "", systemerror.NewArgumentNotOneOf(field, sortedKeys(b.Fields)...)
+	}
+	return column, nil
+}
+
+func (b QueryBuilderSQL) where(criteria Criteria, args *queryArgsSQL) (string, error) {
+	conditions := make([]string, 0, len(criteria.Filters))
+	for _, filter := range criteria.Filters {
+		condition, err := b.condition(filter, args)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	separator := " AND "
+	if criteria.LogicalOperator == data.LogicalOperatorOr {
+		separator = " OR "
+	}
+	clause := strings.Join(conditions, separator)
+	if b.SoftDelete && !criteria.IncludeDeleted {
+		deletedClause := b.AuditColumns.DeletedAt + " IS NULL"
+		if clause == "" {
+			return deletedClause, nil
+		}
+		return "(" + clause + ") AND " + deletedClause, nil
+	}
+	return clause, nil
+}
+
+var comparisonOperatorsSQL = map[data.ComparisonOperator]string{
+	data.OperatorEquals:            "=",
+	data.OperatorNotEquals:         "<>",
+	data.OperatorGreaterThan:       ">",
+	data.OperatorGreaterThanEquals: ">=",
+	data.OperatorLessThan:          "<",
+	data.OperatorLessThanEquals:    "<=",
+	data.OperatorLike:              "LIKE",
+	data.OperatorNotLike:           "NOT LIKE",
+}
+
+func (b QueryBuilderSQL) condition(filter CriteriaFilter, args *queryArgsSQL) (string, error) {
+	column, err := b.column(filter.Field)
+	if err != nil {
+		return "", err
+	}
+
+	switch filter.Operator {
+	case data.OperatorIsNull, data.OperatorNotExists:
+		return column + " IS NULL", nil
+	case data.OperatorIsNotNull, data.OperatorExists:
+		return column + " IS NOT NULL", nil
+	case data.OperatorBetween, data.OperatorNotBetween:
+		if len(filter.Value) != 2 {
+			return "", systemerror.NewArgumentOutOfRange(filter.Field, 2, 2)
+		}
+		operator := " BETWEEN "
+		if filter.Operator == data.OperatorNotBetween {
+			operator = " NOT BETWEEN "
+		}
+		return column + operator + args.add(filter.Value[0]) + " AND " + args.add(filter.Value[1]), nil
+	case data.OperatorIn, data.OperatorNotIn:
+		if len(filter.Value) == 0 {
+			return "", systemerror.NewArgumentOutOfRangeSingle(filter.Field, "min", 1)
+		}
+		placeholders := make([]string, 0, len(filter.Value))
+		for _, v := range filter.Value {
+			placeholders = append(placeholders, args.add(v))
+		}
+		operator := " IN ("
+		if filter.Operator == data.OperatorNotIn {
+			operator = " NOT IN ("
+		}
+		return column + operator + strings.Join(placeholders, ", ") + ")", nil
+	}
+
+	operator, ok := comparisonOperatorsSQL[filter.Operator]
+	if !ok {
+		return "", ErrUnsupportedOperator
+	}
+	if len(filter.Value) != 1 {
+		return "", systemerror.NewArgumentOutOfRange(filter.Field, 1, 1)
+	}
+	return column + " " + operator + " " + args.add(filter.Value[0]), nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
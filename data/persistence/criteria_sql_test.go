@@ -0,0 +1,123 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/security"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+type fixedClock time.Time
+
+func (f fixedClock) Now() time.Time {
+	return time.Time(f)
+}
+
+func newQueryBuilderSQL() persistence.QueryBuilderSQL {
+	builder := persistence.NewQueryBuilderSQL("users", persistence.CriteriaFields{
+		"name": "display_name",
+		"age":  "age",
+	})
+	builder.Auditor.Clock = fixedClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return builder
+}
+
+func TestQueryBuilderSQL_Select(t *testing.T) {
+	tests := []struct {
+		name      string
+		criteria  persistence.Criteria
+		offset    int64
+		wantQuery string
+		wantArgs  []any
+		wantErr   error
+	}{
+		{
+			name:      "excludes deleted by default",
+			wantQuery: "SELECT id FROM users WHERE deleted_at IS NULL",
+		},
+		{
+			name:      "include deleted",
+			criteria:  persistence.Criteria{IncludeDeleted: true},
+			wantQuery: "SELECT id FROM users",
+		},
+		{
+			name: "filters, ordering and paging",
+			criteria: persistence.Criteria{
+				PageSize:        10,
+				Ordering:        persistence.CriteriaOrdering{Field: "age", OrderType: data.OrderTypeDescending},
+				LogicalOperator: data.LogicalOperatorOr,
+				Filters: []persistence.CriteriaFilter{
+					{Field: "name", Operator: data.OperatorLike, Value: []any{"a%"}},
+					{Field: "age", Operator: data.OperatorBetween, Value: []any{18, 30}},
+					{Field: "age", Operator: data.OperatorIn, Value: []any{40, 50}},
+					{Field: "name", Operator: data.OperatorIsNull},
+				},
+			},
+			offset: 20,
+			wantQuery: "SELECT id FROM users WHERE (display_name LIKE $1 OR age BETWEEN $2 AND $3 OR " +
+				"age IN ($4, $5) OR display_name IS NULL) AND deleted_at IS NULL ORDER BY age DESC LIMIT $6 OFFSET $7",
+			wantArgs: []any{"a%", 18, 30, 40, 50, int64(10), int64(20)},
+		},
+		{
+			name: "field not allowed",
+			criteria: persistence.Criteria{
+				Filters: []persistence.CriteriaFilter{{Field: "password", Operator: data.OperatorEquals, Value: []any{"x"}}},
+			},
+			wantErr: systemerror.ErrInvalidArgument,
+		},
+		{
+			name: "invalid value count",
+			criteria: persistence.Criteria{
+				Filters: []persistence.CriteriaFilter{{Field: "age", Operator: data.OperatorEquals}},
+			},
+			wantErr: systemerror.ErrOutOfRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := newQueryBuilderSQL().Select([]string{"id"}, tt.criteria, tt.offset)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestQueryBuilderSQL_Write(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.WithValue(context.Background(), security.PrincipalContextKey,
+		security.PrincipalTemplate{Identifier: "user-1"})
+	builder := newQueryBuilderSQL()
+
+	query, args := builder.Insert(ctx, map[string]any{"id": "1", "age": 20})
+	assert.Equal(t, "INSERT INTO users (age, created_at, created_by, id, updated_at, updated_by) "+
+		"VALUES ($1, $2, $3, $4, $5, $6)", query)
+	assert.Equal(t, []any{20, now, "user-1", "1", now, "user-1"}, args)
+
+	query, args = builder.Update(context.Background(), "id", "1", map[string]any{"age": 21})
+	assert.Equal(t, "UPDATE users SET age = $1, updated_at = $2, updated_by = $3 "+
+		"WHERE id = $4 AND deleted_at IS NULL", query)
+	assert.Equal(t, []any{21, now, "system", "1"}, args)
+
+	query, args = builder.Delete(ctx, "id", "1")
+	assert.Equal(t, "UPDATE users SET deleted_at = $1, deleted_by = $2, updated_at = $3, updated_by = $4 "+
+		"WHERE id = $5 AND deleted_at IS NULL", query)
+	assert.Equal(t, []any{now, "user-1", now, "user-1", "1"}, args)
+
+	builder.SoftDelete = false
+	builder.Placeholder = persistence.PlaceholderQuestion
+	query, args = builder.Delete(ctx, "id", "1")
+	assert.Equal(t, "DELETE FROM users WHERE id = ?", query)
+	assert.Equal(t, []any{"1"}, args)
+}
@@ -0,0 +1,96 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.uber.org/fx"
+
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+// OpenDB opens a database/sql connection pool using ConfigClientSQL. No connection is established.
+func OpenDB(cfg ConfigClientSQL) (*sql.DB, error) {
+	db, err := sql.Open(cfg.DriverName, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
+
+// NewDB opens a database/sql connection pool using ConfigClientSQL.
+//
+// The pool is pinged on application start and closed on application stop.
+func NewDB(lifecycle fx.Lifecycle, cfg ConfigClientSQL) (*sql.DB, error) {
+	db, err := OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if cfg.PingTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, cfg.PingTimeout)
+				defer cancel()
+			}
+			return db.PingContext(ctx)
+		},
+		OnStop: func(_ context.Context) error {
+			return db.Close()
+		},
+	})
+	return db, nil
+}
+
+// NewReplicatedClientSQL allocates a RoutingClientSQL sending read statements to the read replicas
+// specified in ConfigRoutingClientSQL.ReplicaDSNs. Replica pools share ConfigClientSQL pool settings.
+//
+// Replicas are checked on application start, monitored in background and closed on application stop.
+// Unlike NewDB, an unavailable (or hung, see ConfigRoutingClientSQL.HealthCheckTimeout) replica does not
+// prevent the application from starting, it gets ejected instead. Pools already opened are closed if a
+// replica pool cannot be opened.
+func NewReplicatedClientSQL(lifecycle fx.Lifecycle, cfg ConfigRoutingClientSQL, poolCfg ConfigClientSQL,
+	logger logging.Logger, primary ClientSQL) (*RoutingClientSQL, error) {
+	dbs := make([]*sql.DB, 0, len(cfg.ReplicaDSNs))
+	replicas := make([]ClientSQL, 0, len(cfg.ReplicaDSNs))
+	for _, dsn := range cfg.ReplicaDSNs {
+		replicaCfg := poolCfg
+		replicaCfg.DSN = dsn
+		db, err := OpenDB(replicaCfg)
+		if err != nil {
+			return nil, errors.Join(err, closeDBs(dbs))
+		}
+		dbs = append(dbs, db)
+		replicas = append(replicas, NewStatementLoggerClientSQL(logger, db))
+	}
+
+	client := NewRoutingClientSQL(cfg, logger, primary, replicas...)
+	monitorCtx, cancelMonitor := context.WithCancel(context.Background())
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			client.CheckReplicas(ctx)
+			go client.MonitorReplicas(monitorCtx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancelMonitor()
+			return closeDBs(dbs)
+		},
+	})
+	return client, nil
+}
+
+func closeDBs(dbs []*sql.DB) error {
+	errs := make([]error, 0, len(dbs))
+	for _, db := range dbs {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
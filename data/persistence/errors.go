@@ -4,4 +4,18 @@ import "errors"
 
 var (
 	ErrTxContextNotFound = errors.New("transaction context not found")
+	// ErrTxRollbackOnly the transaction was marked as rollback-only by a participating scope, so it was
+	// rolled back instead of committed.
+	ErrTxRollbackOnly = errors.New("transaction marked as rollback-only")
+	// ErrTxPropagationNotSupported the given TransactionPropagation is not supported.
+	ErrTxPropagationNotSupported = errors.New("transaction propagation not supported")
+	// ErrTxNotInitialized the TransactionSQL was not allocated by NewTransactionSQL, so it holds no state to
+	// register callbacks or mark the transaction as rollback-only.
+	ErrTxNotInitialized = errors.New("transaction not initialized")
+	// ErrTxCallback one or more transaction callbacks failed.
+	ErrTxCallback = errors.New("transaction callback failed")
+	// ErrUnsupportedOperator the given data.ComparisonOperator is not supported by the persistence component.
+	ErrUnsupportedOperator = errors.New("unsupported comparison operator")
+	// ErrIncomparableValues the values of a comparison have incompatible types.
+	ErrIncomparableValues = errors.New("incomparable values")
 )
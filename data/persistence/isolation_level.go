@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding"
+	"fmt"
+	"strings"
+)
+
+// IsolationLevelSQL is the transaction isolation level used by TransactionContextFactorySQL. Implements
+// encoding.TextUnmarshaler, so it can be parsed from names (e.g. READ_COMMITTED, "read committed")
+// or from sql.IsolationLevel integer values.
+type IsolationLevelSQL sql.IsolationLevel
+
+var _ encoding.TextUnmarshaler = (*IsolationLevelSQL)(nil)
+
+var isolationLevelNameMap = map[string]sql.IsolationLevel{
+	"DEFAULT":          sql.LevelDefault,
+	"READ_UNCOMMITTED": sql.LevelReadUncommitted,
+	"READ_COMMITTED":   sql.LevelReadCommitted,
+	"WRITE_COMMITTED":  sql.LevelWriteCommitted,
+	"REPEATABLE_READ":  sql.LevelRepeatableRead,
+	"SNAPSHOT":         sql.LevelSnapshot,
+	"SERIALIZABLE":     sql.LevelSerializable,
+	"LINEARIZABLE":     sql.LevelLinearizable,
+}
+
+// UnmarshalText decodes an isolation level from its name or its numeric value.
+func (l *IsolationLevelSQL) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(strings.TrimSpace(string(text)))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if lvl, ok := isolationLevelNameMap[name]; ok {
+		*l = IsolationLevelSQL(lvl)
+		return nil
+	}
+
+	var lvl int
+	if _, err := fmt.Sscanf(name, "%d", &lvl); err != nil || lvl < 0 || lvl > int(sql.LevelLinearizable) {
+		return fmt.Errorf("persistence: invalid isolation level %q", string(text))
+	}
+	*l = IsolationLevelSQL(lvl)
+	return nil
+}
+
+// String returns the isolation level name.
+func (l IsolationLevelSQL) String() string {
+	return sql.IsolationLevel(l).String()
+}
@@ -0,0 +1,37 @@
+package persistence_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+)
+
+func TestIsolationLevelSQL_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp sql.IsolationLevel
+		err bool
+	}{
+		{in: "DEFAULT", exp: sql.LevelDefault},
+		{in: "read committed", exp: sql.LevelReadCommitted},
+		{in: "REPEATABLE-READ", exp: sql.LevelRepeatableRead},
+		{in: "6", exp: sql.LevelSerializable},
+		{in: "99", err: true},
+		{in: "chaos", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var lvl persistence.IsolationLevelSQL
+			err := lvl.UnmarshalText([]byte(tt.in))
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.exp, sql.IsolationLevel(lvl))
+		})
+	}
+}
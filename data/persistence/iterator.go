@@ -0,0 +1,156 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/neutrinocorp/geck/data"
+)
+
+// PageFetchFunc fetches the page identified by token. Token is empty for the first page.
+type PageFetchFunc[T any] func(ctx context.Context, token data.PageToken) (data.Page[T], error)
+
+// NewPageFetchFunc allocates a PageFetchFunc fetching pages of criteria from repo.
+func NewPageFetchFunc[T any](repo PagingRepository[T], criteria Criteria) PageFetchFunc[T] {
+	return func(ctx context.Context, token data.PageToken) (data.Page[T], error) {
+		pageCriteria := criteria
+		pageCriteria.PageToken = token
+		return repo.Find(ctx, pageCriteria)
+	}
+}
+
+// PageIterator streams every item of a paginated dataset, fetching pages on background.
+//
+// At most prefetch + 1 pages are fetched ahead of the consumer. Iteration stops once the last page (no
+// data.Page NextPageToken) is consumed, a page fails to be fetched or context.Context is cancelled.
+//
+// Usage:
+//
+//	it := persistence.NewPageIterator(ctx, persistence.NewPageFetchFunc(repo, criteria), 2)
+//	defer it.Close()
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type PageIterator[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	pages  chan data.Page[T]
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+
+	items   []T
+	current T
+	done    bool
+}
+
+// NewPageIterator allocates a PageIterator and starts fetching pages on background. Prefetch is the number
+// of fetched pages buffered ahead of the consumer; the next page is fetched meanwhile and held until buffered,
+// so zero still fetches a single page ahead.
+//
+// Close must be called to release resources if iteration is stopped before PageIterator.Next returns false.
+func NewPageIterator[T any](ctx context.Context, fetch PageFetchFunc[T], prefetch int) *PageIterator[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &PageIterator[T]{
+		ctx:    ctx,
+		cancel: cancel,
+		pages:  make(chan data.Page[T], max(prefetch, 0)),
+	}
+	it.wg.Add(1)
+	go it.fetchPages(fetch)
+	return it
+}
+
+func (it *PageIterator[T]) fetchPages(fetch PageFetchFunc[T]) {
+	defer it.wg.Done()
+	defer close(it.pages)
+
+	var token data.PageToken
+	for {
+		page, err := fetch(it.ctx, token)
+		if err != nil {
+			it.setErr(err)
+			return
+		}
+
+		select {
+		case it.pages <- page:
+		case <-it.ctx.Done():
+			it.setErr(it.ctx.Err())
+			return
+		}
+
+		// same token would fetch the same page forever
+		if len(page.NextPageToken) == 0 || bytes.Equal(page.NextPageToken, token) {
+			return
+		}
+		token = page.NextPageToken
+	}
+}
+
+// Next advances to the next item, returning false once iteration stopped. Use PageIterator.Err to check
+// whether iteration stopped due an error.
+func (it *PageIterator[T]) Next() bool {
+	for !it.done {
+		if len(it.items) > 0 {
+			it.current = it.items[0]
+			it.items = it.items[1:]
+			return true
+		}
+
+		select {
+		case page, ok := <-it.pages:
+			if !ok {
+				it.done = true
+				it.cancel() // releases context resources, fetching already finished
+				break
+			}
+			it.items = page.Items
+		case <-it.ctx.Done():
+			it.setErr(it.ctx.Err())
+			it.done = true
+		}
+	}
+	var zero T
+	it.current = zero
+	return false
+}
+
+// Item retrieves the current item.
+func (it *PageIterator[T]) Item() T {
+	return it.current
+}
+
+// Err retrieves the error which stopped iteration, if any.
+func (it *PageIterator[T]) Err() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.err
+}
+
+// setErr keeps the first error stopping iteration. Errors caused by PageIterator.Close are ignored.
+func (it *PageIterator[T]) setErr(err error) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.err == nil && !it.closed {
+		it.err = err
+	}
+}
+
+// Close stops iteration and waits for background fetching to finish.
+func (it *PageIterator[T]) Close() {
+	it.mu.Lock()
+	it.closed = true
+	it.mu.Unlock()
+	it.done = true
+	it.cancel()
+	for range it.pages {
+	}
+	it.wg.Wait()
+}
@@ -0,0 +1,106 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/data/persistence/persistencetest"
+)
+
+func newNumberPageFetchFunc(pages int, errAt int) persistence.PageFetchFunc[int] {
+	return func(ctx context.Context, token data.PageToken) (data.Page[int], error) {
+		if err := ctx.Err(); err != nil {
+			return data.Page[int]{}, err
+		}
+		index := 0
+		if len(token) > 0 {
+			index, _ = strconv.Atoi(string(token))
+		}
+		if index == errAt {
+			return data.Page[int]{}, errors.New("fetch failed")
+		}
+		page := data.Page[int]{Items: []int{index * 2, index*2 + 1}}
+		if index+1 < pages {
+			page.NextPageToken = data.PageToken(strconv.Itoa(index + 1))
+		}
+		return page, nil
+	}
+}
+
+func TestPageIterator(t *testing.T) {
+	t.Run("repository", func(t *testing.T) {
+		codec, err := data.NewPageTokenCodec(data.ConfigPageToken{SecretKey: data.PageTokenDefaultEncryptionKey})
+		require.NoError(t, err)
+		repo := persistence.NewRepositoryMemory(persistencetest.ItemFields, codec,
+			func(item persistencetest.Item) string {
+				return item.ID
+			})
+		require.NoError(t, repo.Save(context.Background(), persistencetest.NewItems()...))
+
+		it := persistence.NewPageIterator(context.Background(), persistence.NewPageFetchFunc[persistencetest.Item](repo,
+			persistence.Criteria{
+				PageSize: 1,
+				Ordering: persistence.CriteriaOrdering{Field: "id", OrderType: data.OrderTypeAscending},
+			}), 1)
+		defer it.Close()
+		ids := make([]string, 0, 4)
+		for it.Next() {
+			ids = append(ids, it.Item().ID)
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+	})
+
+	t.Run("error propagation", func(t *testing.T) {
+		it := persistence.NewPageIterator(context.Background(), newNumberPageFetchFunc(5, 2), 0)
+		defer it.Close()
+		items := make([]int, 0)
+		for it.Next() {
+			items = append(items, it.Item())
+		}
+		assert.Equal(t, []int{0, 1, 2, 3}, items)
+		assert.EqualError(t, it.Err(), "fetch failed")
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		const prefetch = 2
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var fetched atomic.Int64
+		fetch := newNumberPageFetchFunc(1000, -1)
+		it := persistence.NewPageIterator(ctx, func(ctx context.Context, token data.PageToken) (data.Page[int], error) {
+			page, err := fetch(ctx, token)
+			if err == nil {
+				fetched.Add(1)
+			}
+			return page, err
+		}, prefetch)
+		count := 0
+		for it.Next() {
+			count++
+			if count == 3 {
+				// second page is being consumed
+				cancel()
+			}
+		}
+		it.Close()
+		assert.ErrorIs(t, it.Err(), context.Canceled)
+		assert.LessOrEqual(t, fetched.Load(), int64(2+prefetch+1))
+	})
+
+	t.Run("early close", func(t *testing.T) {
+		it := persistence.NewPageIterator(context.Background(), newNumberPageFetchFunc(1000, -1), 2)
+		require.True(t, it.Next())
+		it.Close()
+		assert.False(t, it.Next())
+		assert.NoError(t, it.Err())
+	})
+}
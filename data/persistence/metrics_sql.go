@@ -0,0 +1,184 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+// StatementOperationSQL the ClientSQL operation used to run a statement.
+type StatementOperationSQL string
+
+const (
+	StatementOperationPing     StatementOperationSQL = "PING"
+	StatementOperationExec     StatementOperationSQL = "EXEC"
+	StatementOperationPrepare  StatementOperationSQL = "PREPARE"
+	StatementOperationQuery    StatementOperationSQL = "QUERY"
+	StatementOperationQueryRow StatementOperationSQL = "QUERY_ROW"
+	StatementOperationBeginTx  StatementOperationSQL = "BEGIN_TX"
+)
+
+// StatementStatsSQL insights of a single statement execution.
+type StatementStatsSQL struct {
+	// Operation the ClientSQL operation used to run the statement.
+	Operation StatementOperationSQL
+	// Query the executed statement. Normalized if ConfigInstrumentedClientSQL.NormalizeQueries is enabled.
+	Query string
+	// Fingerprint short hash of the normalized statement. Empty if normalization is disabled.
+	Fingerprint string
+	// TraceID trace identifier found in context.Context (if any).
+	TraceID string
+	// Duration time taken by the operation.
+	Duration time.Duration
+	// RowsAffected number of rows affected by an EXEC operation. Negative if not available.
+	RowsAffected int64
+	// Err error returned by the operation (if any).
+	Err error
+	// Status the systemerror.Status of Err. Zero value if the operation succeeded.
+	Status systemerror.Status
+}
+
+// MetricsRecorderSQL records StatementStatsSQL, commonly into a metrics system (e.g. Prometheus, OpenTelemetry).
+type MetricsRecorderSQL interface {
+	RecordStatement(ctx context.Context, stats StatementStatsSQL)
+}
+
+// NewStatusFromErrorSQL maps a database/sql error to a systemerror.Status.
+func NewStatusFromErrorSQL(err error) systemerror.Status {
+	var sysErr systemerror.Error
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &sysErr):
+		return sysErr.Status()
+	case errors.Is(err, sql.ErrNoRows):
+		return systemerror.StatusNotFound
+	case errors.Is(err, context.Canceled):
+		return systemerror.StatusCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return systemerror.StatusDeadlineExceeded
+	case errors.Is(err, sql.ErrTxDone):
+		return systemerror.StatusAborted
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return systemerror.StatusUnavailable
+	default:
+		return systemerror.StatusUnknown
+	}
+}
+
+// DefaultLatencyBucketsSQL upper bounds of the latency histogram used by MetricsSQL.
+var DefaultLatencyBucketsSQL = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// StatementMetricsSQL aggregated metrics of a statement.
+type StatementMetricsSQL struct {
+	Operation StatementOperationSQL `json:"operation"`
+	Query     string                `json:"query"`
+	Count     uint64                `json:"count"`
+	// TotalDuration sum of every observed latency.
+	TotalDuration time.Duration `json:"total_duration"`
+	// Buckets cumulative latency histogram. Keys are bucket upper bounds, +Inf is represented by Count.
+	Buckets map[time.Duration]uint64 `json:"buckets"`
+	// Errors error counts by systemerror.Status name.
+	Errors map[string]uint64 `json:"errors"`
+}
+
+type statementMetricsKey struct {
+	operation StatementOperationSQL
+	query     string
+}
+
+// MetricsSQL is the in-memory MetricsRecorderSQL implementation. Records a latency histogram and error counts
+// by systemerror.Status per statement.
+//
+// Use statement normalization (ConfigInstrumentedClientSQL.NormalizeQueries) to keep the number of tracked
+// statements bounded.
+type MetricsSQL struct {
+	Buckets []time.Duration
+
+	mu         sync.Mutex
+	statements map[statementMetricsKey]*StatementMetricsSQL
+}
+
+var _ MetricsRecorderSQL = (*MetricsSQL)(nil)
+
+// NewMetricsSQL allocates a MetricsSQL instance using DefaultLatencyBucketsSQL.
+func NewMetricsSQL() *MetricsSQL {
+	return &MetricsSQL{
+		Buckets:    DefaultLatencyBucketsSQL,
+		statements: make(map[statementMetricsKey]*StatementMetricsSQL),
+	}
+}
+
+// RecordStatement records the given StatementStatsSQL.
+func (m *MetricsSQL) RecordStatement(_ context.Context, stats StatementStatsSQL) {
+	key := statementMetricsKey{
+		operation: stats.Operation,
+		query:     stats.Query,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	metrics, ok := m.statements[key]
+	if !ok {
+		metrics = &StatementMetricsSQL{
+			Operation: stats.Operation,
+			Query:     stats.Query,
+			Buckets:   make(map[time.Duration]uint64, len(m.Buckets)),
+			Errors:    make(map[string]uint64),
+		}
+		m.statements[key] = metrics
+	}
+	metrics.Count++
+	metrics.TotalDuration += stats.Duration
+	for _, bucket := range m.Buckets {
+		if stats.Duration <= bucket {
+			metrics.Buckets[bucket]++
+		}
+	}
+	if stats.Err != nil {
+		metrics.Errors[stats.Status.String()]++
+	}
+}
+
+// Snapshot returns a copy of the recorded metrics, sorted by operation and query.
+func (m *MetricsSQL) Snapshot() []StatementMetricsSQL {
+	m.mu.Lock()
+	out := make([]StatementMetricsSQL, 0, len(m.statements))
+	for _, metrics := range m.statements {
+		metricsCopy := *metrics
+		metricsCopy.Buckets = make(map[time.Duration]uint64, len(metrics.Buckets))
+		for k, v := range metrics.Buckets {
+			metricsCopy.Buckets[k] = v
+		}
+		metricsCopy.Errors = make(map[string]uint64, len(metrics.Errors))
+		for k, v := range metrics.Errors {
+			metricsCopy.Errors[k] = v
+		}
+		out = append(out, metricsCopy)
+	}
+	m.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Operation != out[j].Operation {
+			return out[i].Operation < out[j].Operation
+		}
+		return out[i].Query < out[j].Query
+	})
+	return out
+}
@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/neutrinocorp/geck/actuator"
+)
+
+// Actuator is the actuator.Actuator implementation for database schemas. Reports the current schema version
+// and marks the schema as degraded if migrations are pending, as the previous schema is still served (e.g.
+// Config.AutoMigrate disabled), so readiness is not lost.
+type Actuator struct {
+	Migrator *Migrator
+}
+
+var _ actuator.Actuator = (*Actuator)(nil)
+
+// NewActuator allocates an Actuator instance.
+func NewActuator(migrator *Migrator) Actuator {
+	return Actuator{
+		Migrator: migrator,
+	}
+}
+
+// State returns the current state of the target component. Returns error if communication with component
+// has failed (not the same as State.Status).
+func (a Actuator) State(ctx context.Context) (actuator.State, error) {
+	version, pending, err := a.Migrator.Version(ctx)
+	if err != nil {
+		return actuator.State{
+			Status:      actuator.StatusDown,
+			Description: err.Error(),
+		}, nil
+	}
+
+	var latestVersion uint64
+	if total := len(a.Migrator.Migrations); total > 0 {
+		latestVersion = a.Migrator.Migrations[total-1].Version
+	}
+	state := actuator.State{
+		Status: actuator.StatusUp,
+		Details: map[string]any{
+			"version":        version,
+			"latest_version": latestVersion,
+			"pending":        pending,
+		},
+	}
+	if pending > 0 {
+		state.Status = actuator.StatusDegraded
+		state.Description = "schema has pending migrations"
+	}
+	return state, nil
+}
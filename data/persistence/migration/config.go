@@ -0,0 +1,13 @@
+package migration
+
+// Config configuration structure for Migrator instances.
+type Config struct {
+	// TableName name of the table tracking applied migrations.
+	TableName string `env:"SQL_MIGRATION_TABLE" envDefault:"schema_migrations"`
+	// LockID identifier of the advisory lock taken while migrating.
+	LockID int64 `env:"SQL_MIGRATION_LOCK_ID" envDefault:"7349128503"`
+	// AutoMigrate applies pending migrations on application start.
+	AutoMigrate bool `env:"SQL_MIGRATION_AUTO_MIGRATE" envDefault:"true"`
+	// DryRun executes migrations and rolls them back, reporting what would have been applied.
+	DryRun bool `env:"SQL_MIGRATION_DRY_RUN" envDefault:"false"`
+}
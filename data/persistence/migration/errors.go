@@ -0,0 +1,18 @@
+package migration
+
+import "errors"
+
+var (
+	// ErrInvalidFileName the migration file does not follow the VERSION_NAME.(up|down).sql nomenclature.
+	ErrInvalidFileName = errors.New("invalid migration file name")
+	// ErrDuplicateVersion two migrations share the same version.
+	ErrDuplicateVersion = errors.New("duplicate migration version")
+	// ErrMissingUpFile the migration has no up file.
+	ErrMissingUpFile = errors.New("missing migration up file")
+	// ErrIrreversible the migration has no down file, so it cannot be reverted.
+	ErrIrreversible = errors.New("irreversible migration")
+	// ErrUnknownVersion the applied version has no migration file.
+	ErrUnknownVersion = errors.New("unknown migration version")
+	// ErrInvalidSteps the number of migrations to revert is negative.
+	ErrInvalidSteps = errors.New("invalid migration steps")
+)
@@ -0,0 +1,37 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Locker acquires a lock so only one program instance (e.g. replica) migrates at a time.
+type Locker interface {
+	// Lock acquires the lock within the given transaction. The lock must be released when tx is completed.
+	Lock(ctx context.Context, tx *sql.Tx, lockID int64) error
+}
+
+// LockerPostgres is the PostgreSQL implementation of Locker, using transaction-level advisory locks.
+type LockerPostgres struct{}
+
+var _ Locker = (*LockerPostgres)(nil)
+
+// NewLockerPostgres allocates a LockerPostgres instance.
+func NewLockerPostgres() LockerPostgres {
+	return LockerPostgres{}
+}
+
+// Lock blocks until the advisory lock is acquired. The lock is released once tx is committed or rolled back.
+func (l LockerPostgres) Lock(ctx context.Context, tx *sql.Tx, lockID int64) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockID)
+	return err
+}
+
+// NoopLocker the no-operation Locker. Use it for databases without advisory locks or single instance programs.
+type NoopLocker struct{}
+
+var _ Locker = (*NoopLocker)(nil)
+
+func (n NoopLocker) Lock(_ context.Context, _ *sql.Tx, _ int64) error {
+	return nil
+}
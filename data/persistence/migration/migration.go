@@ -0,0 +1,103 @@
+package migration
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// Migration a versioned schema change.
+type Migration struct {
+	// Version unique, ascending version of the migration.
+	Version uint64
+	// Name descriptive name of the migration.
+	Name string
+	// Up statements applying the migration.
+	Up string
+	// Down statements reverting the migration. Empty if migration is irreversible.
+	Down string
+}
+
+// Source location of migration files. Commonly, an embed.FS compiled into program binaries.
+type Source struct {
+	// FS file system holding migration files.
+	FS fs.FS
+	// Directory path of migration files within FS.
+	Directory string
+}
+
+// NewMigrationsFromSource reads every migration file from the given Source and returns them sorted by version.
+//
+// Files must follow the nomenclature VERSION_NAME.up.sql and VERSION_NAME.down.sql (e.g. 0001_create_users.up.sql).
+// Every migration requires an up file, while down files are optional.
+func NewMigrationsFromSource(src Source) ([]Migration, error) {
+	dir := src.Directory
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := fs.ReadDir(src.FS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make(map[uint64]*Migration, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		fileName := entry.Name()
+		var baseName string
+		var isUp bool
+		switch {
+		case strings.HasSuffix(fileName, upSuffix):
+			baseName, isUp = strings.TrimSuffix(fileName, upSuffix), true
+		case strings.HasSuffix(fileName, downSuffix):
+			baseName = strings.TrimSuffix(fileName, downSuffix)
+		default:
+			continue
+		}
+
+		versionRaw, name, _ := strings.Cut(baseName, "_")
+		version, err := strconv.ParseUint(versionRaw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, fileName)
+		}
+		content, err := fs.ReadFile(src.FS, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrations[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+		if isUp {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	out := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %d", ErrMissingUpFile, m.Version)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Version < out[j].Version
+	})
+	return out, nil
+}
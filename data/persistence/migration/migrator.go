@@ -0,0 +1,233 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/fx"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+// Migrator applies and reverts versioned Migration(s) through a persistence.ClientSQL, tracking applied
+// versions in Config.TableName.
+//
+// Every operation runs within a single transaction holding a Locker lock, so only one program instance
+// migrates at a time. Databases without transactional DDL (e.g. MySQL) cannot guarantee atomicity.
+type Migrator struct {
+	Config     Config
+	Client     persistence.ClientSQL
+	Locker     Locker
+	Logger     logging.Logger
+	Migrations []Migration
+}
+
+// NewMigratorParams Migrator dependencies.
+type NewMigratorParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    Config
+	Client    persistence.ClientSQL
+	Logger    logging.Logger
+	Source    Source
+	Locker    Locker `optional:"true"`
+}
+
+// NewMigrator allocates a Migrator instance with the migrations found in Source. Uses LockerPostgres if no
+// Locker was specified.
+//
+// Applies pending migrations on application start if Config.AutoMigrate is enabled.
+func NewMigrator(params NewMigratorParams) (*Migrator, error) {
+	migrations, err := NewMigrationsFromSource(params.Source)
+	if err != nil {
+		return nil, err
+	}
+	locker := params.Locker
+	if locker == nil {
+		locker = NewLockerPostgres()
+	}
+	m := &Migrator{
+		Config:     params.Config,
+		Client:     params.Client,
+		Locker:     locker,
+		Logger:     params.Logger,
+		Migrations: migrations,
+	}
+	if params.Config.AutoMigrate {
+		params.Lifecycle.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				_, err := m.Up(ctx)
+				return err
+			},
+		})
+	}
+	return m, nil
+}
+
+type migrateFunc func(ctx context.Context, tx *sql.Tx, applied map[uint64]struct{}) ([]Migration, error)
+
+func (m *Migrator) migrate(ctx context.Context, operation string, fn migrateFunc) (out []Migration, err error) {
+	tx, err := m.Client.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil || m.Config.DryRun {
+			err = errors.Join(err, tx.Rollback())
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	if err = m.Locker.Lock(ctx, tx, m.Config.LockID); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s "+
+		"(version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)",
+		m.Config.TableName)); err != nil {
+		return nil, err
+	}
+	applied, err := m.getAppliedVersions(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	out, err = fn(ctx, tx, applied)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range out {
+		m.Logger.Info().
+			WithField("operation", operation).
+			WithField("version", migration.Version).
+			WithField("name", migration.Name).
+			WithField("dry_run", m.Config.DryRun).
+			WriteWithCtx(ctx, "migrated schema")
+	}
+	return out, nil
+}
+
+func (m *Migrator) getAppliedVersions(ctx context.Context, tx *sql.Tx) (map[uint64]struct{}, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", m.Config.TableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint64]struct{})
+	for rows.Next() {
+		var version uint64
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = struct{}{}
+	}
+	return applied, rows.Err()
+}
+
+// Up applies every pending migration in ascending version order. Returns applied migrations.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.migrate(ctx, "up", func(ctx context.Context, tx *sql.Tx, applied map[uint64]struct{}) ([]Migration, error) {
+		out := make([]Migration, 0, len(m.Migrations))
+		for _, migration := range m.Migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return nil, fmt.Errorf("migration %d: %w", migration.Version, err)
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+				"INSERT INTO %s (version, name, applied_at) VALUES (%d, '%s', CURRENT_TIMESTAMP)",
+				m.Config.TableName, migration.Version, strings.ReplaceAll(migration.Name, "'", "''"))); err != nil {
+				return nil, err
+			}
+			out = append(out, migration)
+		}
+		return out, nil
+	})
+}
+
+// Down reverts the given number of applied migrations in descending version order. Returns reverted migrations.
+//
+// Returns ErrInvalidSteps if steps is negative.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidSteps, steps)
+	}
+	return m.migrate(ctx, "down", func(ctx context.Context, tx *sql.Tx, applied map[uint64]struct{}) ([]Migration, error) {
+		versions := make([]uint64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i] > versions[j]
+		})
+		if steps < len(versions) {
+			versions = versions[:steps]
+		}
+
+		out := make([]Migration, 0, len(versions))
+		for _, version := range versions {
+			migration, ok := m.getMigration(version)
+			if !ok {
+				return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+			} else if migration.Down == "" {
+				return nil, fmt.Errorf("%w: %d", ErrIrreversible, version)
+			}
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return nil, fmt.Errorf("migration %d: %w", migration.Version, err)
+			}
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = %d",
+				m.Config.TableName, migration.Version)); err != nil {
+				return nil, err
+			}
+			out = append(out, migration)
+		}
+		return out, nil
+	})
+}
+
+func (m *Migrator) getMigration(version uint64) (Migration, bool) {
+	i := sort.Search(len(m.Migrations), func(i int) bool {
+		return m.Migrations[i].Version >= version
+	})
+	if i < len(m.Migrations) && m.Migrations[i].Version == version {
+		return m.Migrations[i], true
+	}
+	return Migration{}, false
+}
+
+// Version returns the current schema version (i.e. the highest applied version) along the number of
+// pending migrations. The statement is sent to the primary database if read replicas are used.
+func (m *Migrator) Version(ctx context.Context) (version uint64, pending int, err error) {
+	ctx = persistence.NewReadYourWritesContext(ctx)
+	rows, err := m.Client.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", m.Config.TableName))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	applied := make(map[uint64]struct{})
+	for rows.Next() {
+		var appliedVersion uint64
+		if err = rows.Scan(&appliedVersion); err != nil {
+			return 0, 0, err
+		}
+		applied[appliedVersion] = struct{}{}
+		version = max(version, appliedVersion)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending++
+		}
+	}
+	return version, pending, nil
+}
@@ -0,0 +1,128 @@
+package migration_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/data/persistence/migration"
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+var migrationsFS = fstest.MapFS{
+	"migrations/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id TEXT)")},
+	"migrations/0001_create_users.down.sql": {Data: []byte("DROP TABLE users")},
+	"migrations/0002_add_name.up.sql":       {Data: []byte("ALTER TABLE users ADD name TEXT")},
+	"migrations/README.md":                  {Data: []byte("ignored")},
+}
+
+func newMigrator(t *testing.T, cfg migration.Config) (*migration.Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	migrator, err := migration.NewMigrator(migration.NewMigratorParams{
+		Lifecycle: fxtest.NewLifecycle(t),
+		Config:    cfg,
+		Client:    db,
+		Logger:    logging.NewStdLogger(log.New(io.Discard, "", 0)),
+		Source:    migration.Source{FS: migrationsFS, Directory: "migrations"},
+	})
+	require.NoError(t, err)
+	return migrator, mock
+}
+
+func expectLockedTx(mock sqlmock.Sqlmock, appliedVersions ...uint64) {
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock($1)").WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations " +
+		"(version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP NOT NULL)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, version := range appliedVersions {
+		rows.AddRow(version)
+	}
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(rows)
+}
+
+func TestNewMigrationsFromSource(t *testing.T) {
+	migrations, err := migration.NewMigrationsFromSource(migration.Source{FS: migrationsFS, Directory: "migrations"})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, migration.Migration{
+		Version: 1,
+		Name:    "create_users",
+		Up:      "CREATE TABLE users (id TEXT)",
+		Down:    "DROP TABLE users",
+	}, migrations[0])
+	assert.Equal(t, uint64(2), migrations[1].Version)
+
+	_, err = migration.NewMigrationsFromSource(migration.Source{FS: fstest.MapFS{
+		"0001_foo.down.sql": {Data: []byte("DROP TABLE foo")},
+	}})
+	assert.ErrorIs(t, err, migration.ErrMissingUpFile)
+	_, err = migration.NewMigrationsFromSource(migration.Source{FS: fstest.MapFS{
+		"foo.up.sql": {Data: []byte("CREATE TABLE foo")},
+	}})
+	assert.ErrorIs(t, err, migration.ErrInvalidFileName)
+}
+
+func TestMigrator(t *testing.T) {
+	cfg := migration.Config{TableName: "schema_migrations", LockID: 1}
+	migrator, mock := newMigrator(t, cfg)
+	ctx := context.Background()
+
+	// up applies pending migrations only
+	expectLockedTx(mock, 1)
+	mock.ExpectExec("ALTER TABLE users ADD name TEXT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations (version, name, applied_at) " +
+		"VALUES (2, 'add_name', CURRENT_TIMESTAMP)").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, uint64(2), applied[0].Version)
+
+	// down fails on irreversible migrations
+	expectLockedTx(mock, 1, 2)
+	mock.ExpectRollback()
+	_, err = migrator.Down(ctx, 1)
+	assert.ErrorIs(t, err, migration.ErrIrreversible)
+	_, err = migrator.Down(ctx, -1)
+	assert.ErrorIs(t, err, migration.ErrInvalidSteps)
+
+	// actuator reports schema version, pending migrations degrade the schema as it is still served
+	mock.ExpectQuery("SELECT version FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+	state, err := migration.NewActuator(migrator).State(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, actuator.StatusDegraded, state.Status)
+	assert.Equal(t, map[string]any{
+		"version":        uint64(1),
+		"latest_version": uint64(2),
+		"pending":        1,
+	}, state.Details)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// dry run rolls back
+	cfg.DryRun = true
+	migrator, mock = newMigrator(t, cfg)
+	expectLockedTx(mock, 1)
+	mock.ExpectExec("DROP TABLE users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations WHERE version = 1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+	reverted, err := migrator.Down(ctx, 5)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,20 @@
+package outbox
+
+import "time"
+
+// Config configuration structure for Outbox and Relay instances.
+type Config struct {
+	// TableName name of the outbox table.
+	TableName string `env:"OUTBOX_TABLE" envDefault:"outbox_events"`
+	// PollInterval interval between Relay dispatch cycles.
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	// BatchSize maximum number of events dispatched per Relay cycle.
+	BatchSize int `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	// MaxAttempts number of failed dispatches after which an event is dead-lettered. Must be greater than zero.
+	MaxAttempts int `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	// RetryBackoff base delay before retrying a failed dispatch. Grows exponentially with each attempt. Must
+	// not be negative nor greater than MaxRetryBackoff.
+	RetryBackoff time.Duration `env:"OUTBOX_RETRY_BACKOFF" envDefault:"1s"`
+	// MaxRetryBackoff maximum delay before retrying a failed dispatch.
+	MaxRetryBackoff time.Duration `env:"OUTBOX_MAX_RETRY_BACKOFF" envDefault:"5m"`
+}
@@ -0,0 +1,14 @@
+package outbox
+
+import "errors"
+
+var (
+	// ErrInvalidBatchSize Config.BatchSize is not greater than zero.
+	ErrInvalidBatchSize = errors.New("invalid outbox batch size")
+	// ErrInvalidPollInterval Config.PollInterval is not greater than zero.
+	ErrInvalidPollInterval = errors.New("invalid outbox poll interval")
+	// ErrInvalidMaxAttempts Config.MaxAttempts is not greater than zero.
+	ErrInvalidMaxAttempts = errors.New("invalid outbox max attempts")
+	// ErrInvalidRetryBackoff Config.RetryBackoff is negative or greater than Config.MaxRetryBackoff.
+	ErrInvalidRetryBackoff = errors.New("invalid outbox retry backoff")
+)
@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Status the dispatch status of an Event.
+type Status string
+
+const (
+	// StatusPending event is waiting to be dispatched.
+	StatusPending Status = "PENDING"
+	// StatusDispatched event was published successfully.
+	StatusDispatched Status = "DISPATCHED"
+	// StatusDeadLetter event exceeded Config.MaxAttempts and will not be dispatched again.
+	StatusDeadLetter Status = "DEAD_LETTER"
+)
+
+// Event a message stored in the outbox table, waiting to be published.
+type Event struct {
+	// ID unique identifier of the event. Generated by Outbox if empty.
+	ID string
+	// AggregateKey key of the entity (aggregate) the event belongs to. Events sharing a key are published in
+	// the same order they were enqueued.
+	AggregateKey string
+	// Type name of the event (e.g. user.created).
+	Type string
+	// Payload encoded event.
+	Payload []byte
+	// Attempts number of failed dispatches.
+	Attempts int
+	// CreatedAt time the event was enqueued.
+	CreatedAt time.Time
+}
+
+// Publisher publishes Event(s) into an external system (e.g. message broker, event bus).
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// NewSchemaPostgres returns the PostgreSQL statement creating the outbox table. Add it to the schema migrations.
+func NewSchemaPostgres(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	seq BIGSERIAL PRIMARY KEY,
+	id VARCHAR(64) NOT NULL UNIQUE,
+	aggregate_key VARCHAR(255) NOT NULL,
+	event_type VARCHAR(255) NOT NULL,
+	payload BYTEA,
+	status VARCHAR(16) NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT,
+	available_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	dispatched_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS %[1]s_pending_idx ON %[1]s (aggregate_key, seq) WHERE status = 'PENDING';`, tableName)
+}
@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/identifier"
+)
+
+// Outbox enqueues Event(s) into the outbox table within the current persistence.TransactionSQL, so events are
+// stored atomically along the business data. A Relay dispatches them afterward.
+//
+// Statements use PostgreSQL syntax.
+type Outbox struct {
+	Config    Config
+	IDFactory identifier.Factory
+}
+
+// NewOutbox allocates an Outbox instance.
+func NewOutbox(cfg Config, factory identifier.Factory) Outbox {
+	return Outbox{
+		Config:    cfg,
+		IDFactory: factory,
+	}
+}
+
+// Enqueue stores the given events using the persistence.TransactionSQL found in ctx.
+//
+// Returns persistence.ErrTxContextNotFound if ctx holds no transaction as enqueuing outside a
+// transaction would defeat the outbox purpose.
+func (o Outbox) Enqueue(ctx context.Context, events ...Event) error {
+	txRaw, err := persistence.GetTxFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	tx, ok := txRaw.(persistence.TransactionSQL)
+	if !ok {
+		return persistence.ErrTxContextNotFound
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (id, aggregate_key, event_type, payload, status, attempts, available_at, "+
+		"created_at) VALUES ($1, $2, $3, $4, $5, 0, $6, $6)", o.Config.TableName)
+	now := time.Now().UTC()
+	for _, event := range events {
+		if event.ID == "" {
+			if event.ID, err = o.IDFactory.NewIdentifier(); err != nil {
+				return err
+			}
+		}
+		if _, err = tx.Tx.ExecContext(ctx, query, event.ID, event.AggregateKey, event.Type, event.Payload,
+			string(StatusPending), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/data/persistence/outbox"
+	"github.com/neutrinocorp/geck/identifier"
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+var testConfig = outbox.Config{
+	TableName:       "outbox_events",
+	BatchSize:       10,
+	MaxAttempts:     2,
+	RetryBackoff:    time.Second,
+	MaxRetryBackoff: time.Minute,
+}
+
+type publisherStub struct {
+	published []outbox.Event
+	failures  map[string]error
+}
+
+func (p *publisherStub) Publish(_ context.Context, event outbox.Event) error {
+	if err := p.failures[event.ID]; err != nil {
+		return err
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestOutbox_Enqueue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	logger := logging.NewStdLogger(log.New(io.Discard, "", 0))
+	factory := persistence.NewTransactionContextFactorySQLWithLogger(db, persistence.ConfigTransactionManagerSQL{}, logger)
+	box := outbox.NewOutbox(testConfig, identifier.NewFactoryUUID())
+
+	assert.ErrorIs(t, box.Enqueue(context.Background(), outbox.Event{}), persistence.ErrTxContextNotFound)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WithArgs("evt-1", "user-1", "user.created", []byte("{}"), "PENDING", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WithArgs(sqlmock.AnyArg(), "user-1", "user.updated", []byte("{}"), "PENDING", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+	ctx, err := factory.NewContext(context.Background())
+	require.NoError(t, err)
+	err = box.Enqueue(ctx,
+		outbox.Event{ID: "evt-1", AggregateKey: "user-1", Type: "user.created", Payload: []byte("{}")},
+		outbox.Event{AggregateKey: "user-1", Type: "user.updated", Payload: []byte("{}")},
+	)
+	require.NoError(t, persistence.CloseTransaction(ctx, err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelay_DispatchBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	errPublish := errors.New("broker unavailable")
+	publisher := &publisherStub{
+		failures: map[string]error{"evt-2": errPublish, "evt-3": errPublish},
+	}
+	relay := &outbox.Relay{
+		Config:    testConfig,
+		Client:    db,
+		Publisher: publisher,
+		Logger:    logging.NewStdLogger(log.New(io.Discard, "", 0)),
+	}
+
+	createdAt := time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs("PENDING", sqlmock.AnyArg(), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "aggregate_key", "event_type", "payload", "attempts", "created_at"}).
+			AddRow("evt-1", "user-1", "user.created", []byte("{}"), 0, createdAt).
+			AddRow("evt-2", "user-2", "user.created", []byte("{}"), 0, createdAt).
+			AddRow("evt-3", "user-3", "user.created", []byte("{}"), 1, createdAt))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET status = $1, dispatched_at = $2")).
+		WithArgs("DISPATCHED", sqlmock.AnyArg(), "evt-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET status = $1, attempts = $2")).
+		WithArgs("PENDING", 1, errPublish.Error(), sqlmock.AnyArg(), "evt-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events SET status = $1, attempts = $2")).
+		WithArgs("DEAD_LETTER", 2, errPublish.Error(), sqlmock.AnyArg(), "evt-3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	total, err := relay.DispatchBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, publisher.published, 1)
+	assert.Equal(t, "evt-1", publisher.published[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewRelay_InvalidConfig(t *testing.T) {
+	cfg := testConfig
+	cfg.PollInterval = time.Second
+	params := outbox.NewRelayParams{
+		Lifecycle: fxtest.NewLifecycle(t),
+		Publisher: &publisherStub{},
+		Logger:    logging.NewStdLogger(log.New(io.Discard, "", 0)),
+	}
+
+	params.Config = cfg
+	params.Config.BatchSize = 0
+	_, err := outbox.NewRelay(params)
+	assert.ErrorIs(t, err, outbox.ErrInvalidBatchSize)
+
+	params.Config = cfg
+	params.Config.PollInterval = 0
+	_, err = outbox.NewRelay(params)
+	assert.ErrorIs(t, err, outbox.ErrInvalidPollInterval)
+
+	params.Config = cfg
+	params.Config.MaxAttempts = 0
+	_, err = outbox.NewRelay(params)
+	assert.ErrorIs(t, err, outbox.ErrInvalidMaxAttempts)
+
+	params.Config = cfg
+	params.Config.RetryBackoff = -time.Second
+	_, err = outbox.NewRelay(params)
+	assert.ErrorIs(t, err, outbox.ErrInvalidRetryBackoff)
+
+	params.Config = cfg
+	params.Config.MaxRetryBackoff = -time.Second
+	_, err = outbox.NewRelay(params)
+	assert.ErrorIs(t, err, outbox.ErrInvalidRetryBackoff)
+
+	params.Config = cfg
+	_, err = outbox.NewRelay(params)
+	assert.NoError(t, err)
+}
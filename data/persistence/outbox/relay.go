@@ -0,0 +1,202 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/observability/logging"
+)
+
+// Relay dispatches pending Event(s) from the outbox table to a Publisher.
+//
+// Rows are claimed with SELECT ... FOR UPDATE SKIP LOCKED, so several program instances can relay concurrently.
+// Only the oldest pending event of each aggregate key is claimed per cycle, hence, events sharing a key are
+// published in order. Failed dispatches are retried with exponential backoff and dead-lettered after
+// Config.MaxAttempts.
+type Relay struct {
+	Config    Config
+	Client    persistence.ClientSQL
+	Publisher Publisher
+	Logger    logging.Logger
+}
+
+// NewRelayParams Relay dependencies.
+type NewRelayParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Config    Config
+	Client    persistence.ClientSQL
+	Publisher Publisher
+	Logger    logging.Logger
+}
+
+// NewRelay allocates a Relay instance. Starts relaying on application start and stops on application stop.
+//
+// Returns ErrInvalidBatchSize or ErrInvalidPollInterval if Config would make the Relay spin, and
+// ErrInvalidMaxAttempts or ErrInvalidRetryBackoff if failed dispatches would never be dead-lettered or be
+// retried in the past.
+func NewRelay(params NewRelayParams) (*Relay, error) {
+	if err := validateRelayConfig(params.Config); err != nil {
+		return nil, err
+	}
+	r := &Relay{
+		Config:    params.Config,
+		Client:    params.Client,
+		Publisher: params.Publisher,
+		Logger:    params.Logger,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := sync.WaitGroup{}
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.Run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			wg.Wait()
+			return nil
+		},
+	})
+	return r, nil
+}
+
+func validateRelayConfig(cfg Config) error {
+	if cfg.BatchSize <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidBatchSize, cfg.BatchSize)
+	} else if cfg.PollInterval <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidPollInterval, cfg.PollInterval)
+	} else if cfg.MaxAttempts <= 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMaxAttempts, cfg.MaxAttempts)
+	} else if cfg.RetryBackoff < 0 || cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		return fmt.Errorf("%w: %s (max %s)", ErrInvalidRetryBackoff, cfg.RetryBackoff, cfg.MaxRetryBackoff)
+	}
+	return nil
+}
+
+// Run dispatches batches every Config.PollInterval until ctx is done. Full batches are followed by
+// another cycle right away.
+//
+// Returns right away, logging the error, if Config is invalid (see NewRelay).
+func (r *Relay) Run(ctx context.Context) {
+	if err := validateRelayConfig(r.Config); err != nil {
+		r.Logger.WithError(err).WriteWithCtx(ctx, "invalid outbox relay configuration")
+		return
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		total, err := r.DispatchBatch(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			r.Logger.WithError(err).WriteWithCtx(ctx, "failed to dispatch outbox batch")
+		}
+		if err == nil && total >= r.Config.BatchSize {
+			timer.Reset(0)
+			continue
+		}
+		timer.Reset(r.Config.PollInterval)
+	}
+}
+
+// DispatchBatch claims up to Config.BatchSize pending events and publishes them. Returns the number of
+// claimed events.
+func (r *Relay) DispatchBatch(ctx context.Context) (total int, err error) {
+	tx, err := r.Client.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	now := time.Now().UTC()
+	events, err := r.claim(ctx, tx, now)
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range events {
+		if errPub := r.Publisher.Publish(ctx, event); errPub != nil {
+			if err = r.markFailed(ctx, tx, event, errPub, now); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET status = $1, dispatched_at = $2 WHERE id = $3",
+			r.Config.TableName), string(StatusDispatched), now, event.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(events), nil
+}
+
+func (r *Relay) claim(ctx context.Context, tx *sql.Tx, now time.Time) ([]Event, error) {
+	query := fmt.Sprintf(`SELECT o.id, o.aggregate_key, o.event_type, o.payload, o.attempts, o.created_at FROM %[1]s o
+WHERE o.status = $1 AND o.available_at <= $2
+AND NOT EXISTS (SELECT 1 FROM %[1]s p WHERE p.aggregate_key = o.aggregate_key AND p.status = $1 AND p.seq < o.seq)
+ORDER BY o.seq LIMIT $3 FOR UPDATE SKIP LOCKED`, r.Config.TableName)
+	rows, err := tx.QueryContext(ctx, query, string(StatusPending), now, r.Config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]Event, 0, r.Config.BatchSize)
+	for rows.Next() {
+		event := Event{}
+		if err = rows.Scan(&event.ID, &event.AggregateKey, &event.Type, &event.Payload, &event.Attempts,
+			&event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *Relay) markFailed(ctx context.Context, tx *sql.Tx, event Event, errPub error, now time.Time) error {
+	attempts := event.Attempts + 1
+	status := StatusPending
+	if attempts >= r.Config.MaxAttempts {
+		status = StatusDeadLetter
+	}
+	r.Logger.WithError(errPub).
+		WithField("event_id", event.ID).
+		WithField("event_type", event.Type).
+		WithField("aggregate_key", event.AggregateKey).
+		WithField("attempts", attempts).
+		WithField("status", string(status)).
+		WriteWithCtx(ctx, "failed to publish outbox event")
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET status = $1, attempts = $2, last_error = $3, "+
+		"available_at = $4 WHERE id = $5", r.Config.TableName),
+		string(status), attempts, errPub.Error(), now.Add(r.newBackoff(attempts)), event.ID)
+	return err
+}
+
+func (r *Relay) newBackoff(attempts int) time.Duration {
+	backoff := r.Config.RetryBackoff
+	for i := 1; i < attempts && backoff < r.Config.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.Config.MaxRetryBackoff)
+}
@@ -0,0 +1,55 @@
+package persistence
+
+import (
+	"strconv"
+
+	"github.com/neutrinocorp/geck/data"
+)
+
+// ReadOffsetPageToken retrieves the offset of the OFFSET data.PageToken found in criteria. The token must
+// have been issued for the same criteria (see NewCriteriaHash). Returns zero if criteria has no token.
+func ReadOffsetPageToken(codec data.PageTokenCodec, criteria Criteria) (int64, error) {
+	if len(criteria.PageToken) == 0 {
+		return 0, nil
+	}
+	queryType, value, err := codec.Decode(criteria.PageToken, NewCriteriaHash(criteria))
+	if err != nil {
+		return 0, err
+	} else if queryType != data.PaginationTypeOffset {
+		return 0, data.NewInvalidPageToken("PAGE_TOKEN_TYPE_MISMATCH")
+	}
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, data.NewInvalidPageToken("PAGE_TOKEN_MALFORMED")
+	}
+	return offset, nil
+}
+
+// NewOffsetPage allocates a data.Page using OFFSET data.PageToken instances bound to criteria.
+//
+// Items are the page fetched at offset; totalItems is the size of the whole (filtered) dataset.
+func NewOffsetPage[T any](codec data.PageTokenCodec, criteria Criteria, items []T, offset int64,
+	totalItems int) (data.Page[T], error) {
+	page := data.Page[T]{
+		TotalItems: totalItems,
+		Items:      items,
+	}
+	pageSize := criteria.PageSize
+	queryHash := NewCriteriaHash(criteria)
+	var err error
+	if next := offset + int64(len(items)); pageSize > 0 && next < int64(totalItems) {
+		page.NextPageToken, err = codec.Encode(data.PaginationTypeOffset, strconv.FormatInt(next, 10), queryHash)
+		if err != nil {
+			return data.Page[T]{}, err
+		}
+	}
+	if offset > 0 && pageSize > 0 {
+		prev := max(offset-pageSize, 0)
+		page.PreviousPageToken, err = codec.Encode(data.PaginationTypeOffset, strconv.FormatInt(prev, 10),
+			queryHash)
+		if err != nil {
+			return data.Page[T]{}, err
+		}
+	}
+	return page, nil
+}
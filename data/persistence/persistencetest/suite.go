@@ -0,0 +1,218 @@
+// Package persistencetest provides conformance test suites for persistence implementations.
+package persistencetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+// Item the type stored by PagingRepository instances under test.
+type Item struct {
+	ID    string   `persistence:"id"`
+	Name  string   `persistence:"name"`
+	Age   int      `persistence:"age"`
+	Score *float64 `persistence:"score"`
+	persistence.AuditMetadata
+}
+
+// ItemFields the CriteriaFields PagingRepository instances under test must be configured with.
+var ItemFields = persistence.CriteriaFields{
+	"id":         "id",
+	"name":       "name",
+	"age":        "age",
+	"score":      "score",
+	"created_at": "created_at",
+}
+
+// NewPagingRepositoryFunc allocates a PagingRepository under test, populated with items.
+type NewPagingRepositoryFunc func(t *testing.T, items []Item) persistence.PagingRepository[Item]
+
+// NewItems allocates the dataset used by RunPagingRepositorySuite. Item "5" is soft deleted.
+func NewItems() []Item {
+	score := func(v float64) *float64 {
+		return &v
+	}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := createdAt.Add(time.Hour)
+	return []Item{
+		{ID: "1", Name: "alice", Age: 30, Score: score(9.5), AuditMetadata: persistence.AuditMetadata{
+			CreatedAt: createdAt,
+		}},
+		{ID: "2", Name: "bob", Age: 25, AuditMetadata: persistence.AuditMetadata{
+			CreatedAt: createdAt.Add(time.Minute),
+		}},
+		{ID: "3", Name: "carol", Age: 35, Score: score(7), AuditMetadata: persistence.AuditMetadata{
+			CreatedAt: createdAt.Add(2 * time.Minute),
+		}},
+		{ID: "4", Name: "dave", Age: 25, Score: score(8), AuditMetadata: persistence.AuditMetadata{
+			CreatedAt: createdAt.Add(3 * time.Minute),
+		}},
+		{ID: "5", Name: "erin", Age: 40, AuditMetadata: persistence.AuditMetadata{
+			CreatedAt: createdAt.Add(4 * time.Minute),
+			DeletedAt: &deletedAt,
+		}},
+	}
+}
+
+func filter(field string, operator data.ComparisonOperator, values ...any) persistence.CriteriaFilter {
+	return persistence.CriteriaFilter{Field: field, Operator: operator, Value: values}
+}
+
+// RunPagingRepositorySuite verifies a PagingRepository evaluates persistence.Criteria using the semantics
+// of persistence.QueryBuilderSQL, populating it with NewItems.
+func RunPagingRepositorySuite(t *testing.T, newRepo NewPagingRepositoryFunc) {
+	t.Run("criteria", func(t *testing.T) {
+		repo := newRepo(t, NewItems())
+		tests := []struct {
+			name string
+			in   persistence.Criteria
+			// ordered indicates whether wantIDs order must be preserved.
+			ordered bool
+			wantIDs []string
+			wantErr error
+		}{
+			{name: "no filters excludes deleted", wantIDs: []string{"1", "2", "3", "4"}},
+			{name: "include deleted", in: persistence.Criteria{IncludeDeleted: true},
+				wantIDs: []string{"1", "2", "3", "4", "5"}},
+			{name: "equals", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorEquals, 25)}}, wantIDs: []string{"2", "4"}},
+			{name: "not equals", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorNotEquals, 25)}}, wantIDs: []string{"1", "3"}},
+			{name: "not equals skips nulls", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorNotEquals, 8.0)}}, wantIDs: []string{"1", "3"}},
+			{name: "greater than", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorGreaterThan, 30)}}, wantIDs: []string{"3"}},
+			{name: "greater than equals", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorGreaterThanEquals, 30)}}, wantIDs: []string{"1", "3"}},
+			{name: "less than", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorLessThan, 30)}}, wantIDs: []string{"2", "4"}},
+			{name: "less than equals", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorLessThanEquals, 8)}}, wantIDs: []string{"3", "4"}},
+			{name: "between", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorBetween, 25, 30)}}, wantIDs: []string{"1", "2", "4"}},
+			{name: "not between", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorNotBetween, 25, 30)}}, wantIDs: []string{"3"}},
+			{name: "between time", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("created_at", data.OperatorBetween, time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC),
+					time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC))}}, wantIDs: []string{"2", "3"}},
+			{name: "in", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("name", data.OperatorIn, "alice", "carol")}}, wantIDs: []string{"1", "3"}},
+			{name: "not in", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("name", data.OperatorNotIn, "alice", "carol")}}, wantIDs: []string{"2", "4"}},
+			{name: "in with null", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorIn, nil, 8.0)}}, wantIDs: []string{"4"}},
+			{name: "not in with null", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorNotIn, nil, 8.0)}}, wantIDs: []string{}},
+			{name: "like", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("name", data.OperatorLike, "%a%")}}, wantIDs: []string{"1", "3", "4"}},
+			{name: "like single char", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("name", data.OperatorLike, "_ob")}}, wantIDs: []string{"2"}},
+			{name: "not like", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("name", data.OperatorNotLike, "%a%")}}, wantIDs: []string{"2"}},
+			{name: "is null", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorIsNull)}}, wantIDs: []string{"2"}},
+			{name: "is not null", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorIsNotNull)}}, wantIDs: []string{"1", "3", "4"}},
+			{name: "not exists", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorNotExists)}}, wantIDs: []string{"2"}},
+			{name: "exists", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("score", data.OperatorExists)}}, wantIDs: []string{"1", "3", "4"}},
+			{name: "and", in: persistence.Criteria{
+				LogicalOperator: data.LogicalOperatorAnd,
+				Filters: []persistence.CriteriaFilter{
+					filter("age", data.OperatorEquals, 25),
+					filter("name", data.OperatorEquals, "dave"),
+				}}, wantIDs: []string{"4"}},
+			{name: "or", in: persistence.Criteria{
+				LogicalOperator: data.LogicalOperatorOr,
+				Filters: []persistence.CriteriaFilter{
+					filter("age", data.OperatorEquals, 25),
+					filter("name", data.OperatorEquals, "carol"),
+				}}, wantIDs: []string{"2", "3", "4"}},
+			{name: "order descending", in: persistence.Criteria{
+				Ordering: persistence.CriteriaOrdering{Field: "name", OrderType: data.OrderTypeDescending},
+			}, ordered: true, wantIDs: []string{"4", "3", "2", "1"}},
+			{name: "order ascending nulls last", in: persistence.Criteria{
+				Ordering: persistence.CriteriaOrdering{Field: "score", OrderType: data.OrderTypeAscending},
+			}, ordered: true, wantIDs: []string{"3", "4", "1", "2"}},
+			{name: "order descending nulls first", in: persistence.Criteria{
+				Ordering: persistence.CriteriaOrdering{Field: "score", OrderType: data.OrderTypeDescending},
+			}, ordered: true, wantIDs: []string{"2", "1", "4", "3"}},
+			{name: "field not allowed", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("password", data.OperatorEquals, "x")}}, wantErr: systemerror.ErrInvalidArgument},
+			{name: "invalid values", in: persistence.Criteria{Filters: []persistence.CriteriaFilter{
+				filter("age", data.OperatorBetween, 25)}}, wantErr: systemerror.ErrOutOfRange},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := repo.Find(context.Background(), tt.in)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+					return
+				}
+				require.NoError(t, err)
+				gotIDs := make([]string, 0, len(page.Items))
+				for _, item := range page.Items {
+					gotIDs = append(gotIDs, item.ID)
+				}
+				if tt.ordered {
+					assert.Equal(t, tt.wantIDs, gotIDs)
+				} else {
+					assert.ElementsMatch(t, tt.wantIDs, gotIDs)
+				}
+				assert.Equal(t, len(tt.wantIDs), page.TotalItems)
+			})
+		}
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		repo := newRepo(t, NewItems())
+		criteria := persistence.Criteria{
+			PageSize: 3,
+			Ordering: persistence.CriteriaOrdering{Field: "name", OrderType: data.OrderTypeAscending},
+		}
+		page, err := repo.Find(context.Background(), criteria)
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 3)
+		assert.Equal(t, 4, page.TotalItems)
+		assert.Empty(t, page.PreviousPageToken)
+		require.NotEmpty(t, page.NextPageToken)
+
+		criteria.PageToken = page.NextPageToken
+		page, err = repo.Find(context.Background(), criteria)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, "4", page.Items[0].ID)
+		assert.Empty(t, page.NextPageToken)
+		require.NotEmpty(t, page.PreviousPageToken)
+
+		criteria.PageToken = page.PreviousPageToken
+		page, err = repo.Find(context.Background(), criteria)
+		require.NoError(t, err)
+		require.Len(t, page.Items, 3)
+		assert.Equal(t, "1", page.Items[0].ID)
+
+		nextPageToken := page.NextPageToken
+		criteria.Filters = []persistence.CriteriaFilter{filter("age", data.OperatorEquals, 25)}
+		_, err = repo.Find(context.Background(), criteria)
+		assert.ErrorIs(t, err, data.ErrInvalidPageToken, "token must be bound to the criteria which issued it")
+
+		criteria.Filters = nil
+		criteria.PageToken = append(data.PageToken{}, nextPageToken...)
+		criteria.PageToken[len(criteria.PageToken)-1] ^= 1
+		_, err = repo.Find(context.Background(), criteria)
+		assert.ErrorIs(t, err, data.ErrInvalidPageToken, "tampered token")
+
+		criteria.PageToken = data.PageToken("invalid")
+		_, err = repo.Find(context.Background(), criteria)
+		assert.ErrorIs(t, err, data.ErrInvalidPageToken)
+	})
+}
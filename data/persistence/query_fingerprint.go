@@ -0,0 +1,81 @@
+package persistence
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NormalizeQuerySQL replaces literals (strings, numbers) and positional parameters (e.g. $1, ?, :name) of
+// the given statement with a '?' placeholder, collapses whitespaces and IN lists. Statements only differing
+// in their arguments are normalized into the same value, avoiding high-cardinality metric labels.
+func NormalizeQuerySQL(query string) string {
+	buf := strings.Builder{}
+	buf.Grow(len(query))
+	runes := []rune(strings.TrimSpace(query))
+	pendingSpace := false
+	writeRune := func(r rune) {
+		if pendingSpace && buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+		pendingSpace = false
+		buf.WriteRune(r)
+	}
+	isIdentRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+	}
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			pendingSpace = true
+		case r == '\'':
+			// string literal, '' is an escaped quote
+			for i++; i < len(runes); i++ {
+				if runes[i] != '\'' {
+					continue
+				}
+				if i+1 < len(runes) && runes[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+			writeRune('?')
+		case (r == '$' || r == ':' || r == '@') && i+1 < len(runes) && isIdentRune(runes[i+1]) &&
+			(i == 0 || runes[i-1] != ':'):
+			for i+1 < len(runes) && isIdentRune(runes[i+1]) {
+				i++
+			}
+			writeRune('?')
+		case unicode.IsDigit(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			writeRune('?')
+		default:
+			writeRune(r)
+		}
+	}
+	return collapseInListSQL(buf.String())
+}
+
+// collapseInListSQL collapses placeholder lists (e.g. IN (?, ?, ?)) into a single placeholder (IN (?)).
+func collapseInListSQL(query string) string {
+	for {
+		collapsed := strings.ReplaceAll(query, "?, ?", "?")
+		collapsed = strings.ReplaceAll(collapsed, "?,?", "?")
+		if collapsed == query {
+			return query
+		}
+		query = collapsed
+	}
+}
+
+// NewQueryFingerprintSQL returns a short hash of the normalized statement (see NormalizeQuerySQL).
+func NewQueryFingerprintSQL(query string) string {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(NormalizeQuerySQL(query)))
+	return strconv.FormatUint(hasher.Sum64(), 16)
+}
@@ -0,0 +1,30 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/neutrinocorp/geck/data"
+)
+
+// PagingRepository a repository able to fetch chunks of a dataset using Criteria.
+type PagingRepository[T any] interface {
+	// Find retrieves a data.Page of items matching criteria.
+	Find(ctx context.Context, criteria Criteria) (data.Page[T], error)
+}
+
+// SoftDeletable a type able to be marked as deleted without being removed from storage. AuditMetadata
+// implements this interface, so types embedding it are soft deletable.
+type SoftDeletable interface {
+	IsDeleted() bool
+}
+
+// Repository a PagingRepository able to store, retrieve and remove items by their key.
+type Repository[K comparable, T any] interface {
+	PagingRepository[T]
+	// Save stores items, replacing existing ones with the same key.
+	Save(ctx context.Context, items ...T) error
+	// Get retrieves the item with the given key.
+	Get(ctx context.Context, key K) (T, error)
+	// Delete removes the item with the given key.
+	Delete(ctx context.Context, key K) error
+}
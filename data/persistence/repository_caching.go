@@ -0,0 +1,341 @@
+package persistence
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/internal/hashing"
+	"github.com/neutrinocorp/geck/security"
+)
+
+func init() {
+	// criteria filter values are hashed using gob, time values are not registered by default
+	gob.Register(time.Time{})
+}
+
+const (
+	// RepositoryCachingDefaultItemTTL default RepositoryCaching.ItemTTL.
+	RepositoryCachingDefaultItemTTL = 5 * time.Minute
+	// RepositoryCachingDefaultPageTTL default RepositoryCaching.PageTTL.
+	RepositoryCachingDefaultPageTTL = time.Minute
+	// RepositoryCachingDefaultMaxPageRefs default RepositoryCaching.MaxPageRefs.
+	RepositoryCachingDefaultMaxPageRefs = 128
+)
+
+const (
+	repositoryCachingItemPrefix    = "e:"
+	repositoryCachingPagePrefix    = "p:"
+	repositoryCachingRefPrefix     = "r:"
+	repositoryCachingGenerationKey = "g"
+)
+
+// RepositoryCaching a Repository decorator caching items and query results (data.Page) into a
+// caching.Cache.
+//
+// Items and pages are scoped to the tenant of security.GetTenantFromContext (empty if none), so entries
+// cached for a tenant are never served to another one. Keys are formatted as follows, where SCOPE is
+// len(TENANT) + ":" + TENANT + ":" so tenants cannot collide:
+//
+//   - KeyPrefix + "e:" + {SCOPE} + {ENTITY_ID}: an item.
+//   - KeyPrefix + "p:" + {SCOPE} + {CRITERIA_HASH}: a data.Page, where CRITERIA_HASH is computed with
+//     hashing.NewHashString(criteria).
+//   - KeyPrefix + "r:" + {ENTITY_ID}: the list of item and page keys holding an item, of every scope.
+//   - KeyPrefix + "g": a counter incremented by every invalidation.
+//
+// Cache is bypassed within transactions, so uncommitted items are never cached, and if a tenant was
+// requested (security.NewTenantContext) but could not be resolved.
+//
+// Mutating an item (Save, Delete) removes every entry holding it, in every scope, and its reference list
+// (see Invalidate), once the transaction in ctx (if any) is committed. Pages which would include a newly
+// created item do not reference it yet, hence are refreshed once PageTTL elapses. An item or page is only
+// cached if no invalidation happened since it was read from Next (see "g"), so entries read before a
+// concurrent mutation are never kept.
+//
+// Reference lists hold each key once, expire along the entries they reference and are bounded to
+// MaxPageRefs keys, evicting the entries of the oldest ones. PageTTL must be lower than
+// data.ConfigPageToken.TTL so cached page tokens are still valid when served.
+type RepositoryCaching[K comparable, T any] struct {
+	Next      Repository[K, T]
+	Cache     caching.Cache
+	Codec     caching.Codec
+	KeyPrefix string
+	// ItemTTL time to live of cached items. Items are not cached if not positive.
+	ItemTTL time.Duration
+	// PageTTL time to live of cached pages. Pages are not cached if not positive.
+	PageTTL time.Duration
+	// MaxPageRefs maximum number of entries (items and pages) referencing an item. Unbounded if not positive.
+	MaxPageRefs int64
+
+	keyFunc func(T) K
+	group   singleflight.Group
+}
+
+var _ Repository[string, any] = (*RepositoryCaching[string, any])(nil)
+
+// NewRepositoryCaching allocates a RepositoryCaching instance decorating next, using
+// RepositoryCachingDefaultItemTTL, RepositoryCachingDefaultPageTTL and RepositoryCachingDefaultMaxPageRefs.
+// keyFunc retrieves the unique key of an item.
+func NewRepositoryCaching[K comparable, T any](next Repository[K, T], cache caching.Cache, codec caching.Codec,
+	keyPrefix string, keyFunc func(T) K) *RepositoryCaching[K, T] {
+	return &RepositoryCaching[K, T]{
+		Next:        next,
+		Cache:       cache,
+		Codec:       codec,
+		KeyPrefix:   keyPrefix,
+		ItemTTL:     RepositoryCachingDefaultItemTTL,
+		PageTTL:     RepositoryCachingDefaultPageTTL,
+		MaxPageRefs: RepositoryCachingDefaultMaxPageRefs,
+		keyFunc:     keyFunc,
+	}
+}
+
+// getScope returns the key scope of the current operation, i.e. the tenant of security.GetTenantFromContext.
+// Returns ok = false if Cache must be bypassed, as ctx holds a transaction or a tenant was requested but
+// could not be resolved.
+func (r *RepositoryCaching[K, T]) getScope(ctx context.Context) (scope string, ok bool) {
+	if _, err := GetTxFromContext(ctx); err == nil {
+		return "", false
+	}
+	tenantID, err := security.GetTenantFromContext(ctx)
+	if err != nil {
+		if requested, _ := ctx.Value(security.TenantContextKey).(string); requested != "" {
+			return "", false
+		}
+		tenantID = ""
+	}
+	return strconv.Itoa(len(tenantID)) + ":" + tenantID + ":", true
+}
+
+func (r *RepositoryCaching[K, T]) formatItemKey(scope string, key K) string {
+	return r.KeyPrefix + repositoryCachingItemPrefix + scope + fmt.Sprint(key)
+}
+
+func (r *RepositoryCaching[K, T]) formatRefKey(key K) string {
+	return r.KeyPrefix + repositoryCachingRefPrefix + fmt.Sprint(key)
+}
+
+// getGeneration reads the invalidation counter. Missing counters are zero.
+func (r *RepositoryCaching[K, T]) getGeneration(ctx context.Context) (int64, error) {
+	value, err := r.Cache.Get(ctx, r.KeyPrefix+repositoryCachingGenerationKey)
+	if errors.Is(err, caching.ErrCacheMiss) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(value), 10, 64)
+}
+
+func (r *RepositoryCaching[K, T]) Save(ctx context.Context, items ...T) error {
+	if err := r.Next.Save(ctx, items...); err != nil {
+		return err
+	}
+	keys := make([]K, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, r.keyFunc(item))
+	}
+	return r.invalidateAfterCommit(ctx, keys...)
+}
+
+// Get retrieves the item of key, reading it from Cache if present. Concurrent misses of the same key share
+// a single Next.Get call. Cache failures are treated as misses.
+func (r *RepositoryCaching[K, T]) Get(ctx context.Context, key K) (T, error) {
+	scope, ok := r.getScope(ctx)
+	if !ok || r.ItemTTL <= 0 {
+		return r.Next.Get(ctx, key)
+	}
+	itemKey := r.formatItemKey(scope, key)
+	if cached, errCache := r.Cache.Get(ctx, itemKey); errCache == nil {
+		var item T
+		if errCache = r.Codec.Unmarshal(cached, &item); errCache == nil {
+			return item, nil
+		}
+	}
+
+	// the load is detached from the cancellation of ctx, so a cancelled caller does not fail others
+	resultCh := r.group.DoChan(itemKey, func() (any, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		generation, errCache := r.getGeneration(loadCtx)
+		item, err := r.Next.Get(loadCtx, key)
+		if err != nil || errCache != nil {
+			return item, err
+		}
+		if encoded, errCache := r.Codec.Marshal(item); errCache == nil {
+			r.storeEntry(loadCtx, itemKey, encoded, r.ItemTTL, generation, key)
+		}
+		return item, nil
+	})
+	select {
+	case res := <-resultCh:
+		item, _ := res.Val.(T)
+		return item, res.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (r *RepositoryCaching[K, T]) Delete(ctx context.Context, key K) error {
+	if err := r.Next.Delete(ctx, key); err != nil {
+		return err
+	}
+	return r.invalidateAfterCommit(ctx, key)
+}
+
+// Find retrieves a data.Page of items matching criteria, reading it from Cache if present. Cache failures
+// are treated as misses.
+func (r *RepositoryCaching[K, T]) Find(ctx context.Context, criteria Criteria) (data.Page[T], error) {
+	scope, ok := r.getScope(ctx)
+	if !ok || r.PageTTL <= 0 {
+		return r.Next.Find(ctx, criteria)
+	}
+	criteriaHash, err := hashing.NewHashString(criteria)
+	if err != nil {
+		return r.Next.Find(ctx, criteria)
+	}
+
+	pageKey := r.KeyPrefix + repositoryCachingPagePrefix + scope + criteriaHash
+	if cached, errCache := r.Cache.Get(ctx, pageKey); errCache == nil {
+		page := data.Page[T]{}
+		if errCache = r.Codec.Unmarshal(cached, &page); errCache == nil {
+			return page, nil
+		}
+	}
+
+	// generation is read before querying Next so invalidations racing with the query are detected
+	generation, errCache := r.getGeneration(ctx)
+	page, err := r.Next.Find(ctx, criteria)
+	if err != nil {
+		return data.Page[T]{}, err
+	} else if errCache != nil {
+		return page, nil
+	}
+	if encoded, errCache := r.Codec.Marshal(page); errCache == nil {
+		keys := make([]K, 0, len(page.Items))
+		for _, item := range page.Items {
+			keys = append(keys, r.keyFunc(item))
+		}
+		r.storeEntry(ctx, pageKey, encoded, r.PageTTL, generation, keys...)
+	}
+	return page, nil
+}
+
+// storeEntry stores value under key after registering key into the reference list of each item of refs,
+// so the entry is never cached without being reachable from its items. Reference lists are then extended to
+// outlive the entry. The entry is removed if an invalidation happened since generation was read. Cache
+// failures are ignored as the entry is simply not cached.
+func (r *RepositoryCaching[K, T]) storeEntry(ctx context.Context, key string, value []byte, ttl time.Duration,
+	generation int64, refs ...K) {
+	refKeys := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		refKey := r.formatRefKey(ref)
+		if err := r.addRef(ctx, refKey, key); err != nil {
+			return
+		}
+		refKeys = append(refKeys, refKey)
+	}
+	if err := r.Cache.SetWithTTL(ctx, key, value, ttl); err != nil {
+		return
+	}
+	// every reference list shares the same TTL, so lists always outlive the entries they reference
+	refTTL := max(r.ItemTTL, r.PageTTL)
+	for _, refKey := range refKeys {
+		if err := r.Cache.Touch(ctx, refKey, refTTL); err != nil {
+			_ = r.Cache.Delete(ctx, key)
+			return
+		}
+	}
+	if current, err := r.getGeneration(ctx); err != nil || current != generation {
+		_ = r.Cache.Delete(ctx, key)
+	}
+}
+
+// addRef appends key to the reference list refKey, removing previous occurrences. The list is bounded to
+// MaxPageRefs keys, removing the entries of trimmed references as they could no longer be invalidated.
+func (r *RepositoryCaching[K, T]) addRef(ctx context.Context, refKey, key string) error {
+	err := r.Cache.Remove(ctx, refKey, []byte(key))
+	if err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+		return err
+	}
+	if err = r.Cache.Add(ctx, refKey, []byte(key)); err != nil || r.MaxPageRefs <= 0 {
+		return err
+	}
+
+	keys, err := r.Cache.List(ctx, refKey)
+	if err != nil || int64(len(keys)) <= r.MaxPageRefs {
+		return err
+	}
+	if err = r.Cache.Trim(ctx, refKey, r.MaxPageRefs); err != nil {
+		return err
+	}
+	// references added concurrently may be trimmed as well, keys kept are compared to be exhaustive
+	keptKeys, err := r.Cache.List(ctx, refKey)
+	if err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+		return err
+	}
+	kept := make(map[string]struct{}, len(keptKeys))
+	for _, keptKey := range keptKeys {
+		kept[string(keptKey)] = struct{}{}
+	}
+	for _, trimmedKey := range keys {
+		if _, ok := kept[string(trimmedKey)]; ok {
+			continue
+		}
+		if err = r.Cache.Delete(ctx, string(trimmedKey)); err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidateAfterCommit invalidates keys once the transaction in ctx is committed, or right away if ctx
+// holds no transaction.
+func (r *RepositoryCaching[K, T]) invalidateAfterCommit(ctx context.Context, keys ...K) error {
+	err := RegisterAfterCommit(ctx, func(ctx context.Context) error {
+		return r.Invalidate(ctx, keys...)
+	})
+	if errors.Is(err, ErrTxContextNotFound) {
+		return r.Invalidate(ctx, keys...)
+	}
+	return err
+}
+
+// Invalidate removes every cached entry holding items with the given keys, in every scope, and their
+// reference lists.
+func (r *RepositoryCaching[K, T]) Invalidate(ctx context.Context, keys ...K) error {
+	errs := make([]error, 0)
+	// generation is incremented before reading references so entries stored concurrently are either
+	// referenced or discarded by storeEntry
+	if _, err := r.Cache.Increment(ctx, r.KeyPrefix+repositoryCachingGenerationKey, 1); err != nil {
+		errs = append(errs, err)
+	}
+	for _, key := range keys {
+		refKey := r.formatRefKey(key)
+		cachedKeys, err := r.Cache.List(ctx, refKey)
+		if err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+			errs = append(errs, err)
+		}
+
+		cacheKeys := make([]string, 0, len(cachedKeys)+1)
+		for _, cachedKey := range cachedKeys {
+			cacheKeys = append(cacheKeys, string(cachedKey))
+		}
+		// references are kept if they could not be read, so a later invalidation is able to remove their entries
+		if err == nil {
+			cacheKeys = append(cacheKeys, refKey)
+		}
+		for _, cacheKey := range cacheKeys {
+			if err = r.Cache.Delete(ctx, cacheKey); err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,303 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/data/persistence/persistencetest"
+	"github.com/neutrinocorp/geck/internal/hashing"
+	"github.com/neutrinocorp/geck/security"
+)
+
+type findCountingRepositoryTest struct {
+	persistence.Repository[string, persistencetest.Item]
+	finds  int
+	gets   int
+	onFind func()
+	onGet  func()
+}
+
+func (r *findCountingRepositoryTest) Get(ctx context.Context, key string) (persistencetest.Item, error) {
+	r.gets++
+	item, err := r.Repository.Get(ctx, key)
+	if r.onGet != nil {
+		r.onGet()
+	}
+	return item, err
+}
+
+func (r *findCountingRepositoryTest) Find(ctx context.Context,
+	criteria persistence.Criteria) (data.Page[persistencetest.Item], error) {
+	r.finds++
+	page, err := r.Repository.Find(ctx, criteria)
+	if r.onFind != nil {
+		r.onFind()
+	}
+	return page, err
+}
+
+func newRepositoryCachingTest(t *testing.T,
+	items []persistencetest.Item) (*persistence.RepositoryCaching[string, persistencetest.Item],
+	*findCountingRepositoryTest, caching.Cache) {
+	codec, err := data.NewPageTokenCodec(data.ConfigPageToken{SecretKey: data.PageTokenDefaultEncryptionKey})
+	require.NoError(t, err)
+	keyFunc := func(item persistencetest.Item) string {
+		return item.ID
+	}
+	memory := persistence.NewRepositoryMemory(persistencetest.ItemFields, codec, keyFunc)
+	require.NoError(t, memory.Save(context.Background(), items...))
+
+	db, err := bigcache.New(context.Background(), bigcache.DefaultConfig(time.Minute))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	cache := caching.NewCacheEmbedded(db)
+	next := &findCountingRepositoryTest{Repository: memory}
+	return persistence.NewRepositoryCaching[string, persistencetest.Item](next, cache, caching.CodecJSON{},
+		"items:", keyFunc), next, cache
+}
+
+func TestRepositoryCaching(t *testing.T) {
+	persistencetest.RunPagingRepositorySuite(t,
+		func(t *testing.T, items []persistencetest.Item) persistence.PagingRepository[persistencetest.Item] {
+			repo, _, _ := newRepositoryCachingTest(t, items)
+			return repo
+		})
+}
+
+func TestRepositoryCaching_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	repo, next, cache := newRepositoryCachingTest(t, persistencetest.NewItems())
+	adults := persistence.Criteria{
+		Filters: []persistence.CriteriaFilter{
+			{Field: "age", Operator: data.OperatorGreaterThanEquals, Value: []any{30}},
+		},
+		LogicalOperator: data.LogicalOperatorAnd,
+	}
+	young := persistence.Criteria{
+		Filters: []persistence.CriteriaFilter{
+			{Field: "age", Operator: data.OperatorLessThan, Value: []any{30}},
+		},
+		LogicalOperator: data.LogicalOperatorAnd,
+	}
+
+	page, err := repo.Find(ctx, adults)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	_, err = repo.Find(ctx, young)
+	require.NoError(t, err)
+	cached, err := repo.Find(ctx, adults)
+	require.NoError(t, err)
+	assert.Equal(t, page.Items, cached.Items)
+	assert.Equal(t, page.TotalItems, cached.TotalItems)
+	assert.Equal(t, 2, next.finds)
+
+	refs, err := cache.List(ctx, "items:r:1")
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+
+	item, err := repo.Get(ctx, "1")
+	require.NoError(t, err)
+	_, err = cache.Get(ctx, "items:e:0::1")
+	require.NoError(t, err)
+	refs, err = cache.List(ctx, "items:r:1")
+	require.NoError(t, err)
+	assert.Len(t, refs, 2)
+	item.Age = 20
+	require.NoError(t, repo.Save(ctx, item))
+
+	// pages containing item are evicted, others are kept
+	_, err = cache.List(ctx, "items:r:1")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+	_, err = cache.Get(ctx, "items:e:0::1")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+	page, err = repo.Find(ctx, adults)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, 3, next.finds)
+	_, err = repo.Find(ctx, young)
+	require.NoError(t, err)
+	assert.Equal(t, 3, next.finds)
+
+	require.NoError(t, repo.Delete(ctx, "3"))
+	page, err = repo.Find(ctx, adults)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.Equal(t, 4, next.finds)
+}
+
+func TestRepositoryCaching_Tenant(t *testing.T) {
+	repo, next, cache := newRepositoryCachingTest(t, persistencetest.NewItems())
+	newTenantCtx := func(tenantID string) context.Context {
+		return context.WithValue(context.Background(), security.PrincipalContextKey,
+			security.PrincipalTemplate{Identifier: "user-" + tenantID, Tenant: tenantID})
+	}
+	ctxA, ctxB := newTenantCtx("tenant-a"), newTenantCtx("tenant-b")
+	criteria := persistence.Criteria{LogicalOperator: data.LogicalOperatorAnd}
+
+	_, err := repo.Find(ctxA, criteria)
+	require.NoError(t, err)
+	_, err = repo.Find(ctxB, criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.finds)
+	_, err = repo.Get(ctxA, "1")
+	require.NoError(t, err)
+	_, err = cache.Get(ctxA, "items:e:8:tenant-a:1")
+	require.NoError(t, err)
+	_, err = cache.Get(ctxB, "items:e:8:tenant-b:1")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+
+	// mutations without tenant (e.g. background jobs) invalidate every scope
+	require.NoError(t, repo.Invalidate(context.Background(), "1"))
+	_, err = cache.Get(ctxA, "items:e:8:tenant-a:1")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+	_, err = repo.Find(ctxA, criteria)
+	require.NoError(t, err)
+	_, err = repo.Find(ctxB, criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 4, next.finds)
+
+	// requested tenants that could not be resolved bypass the cache
+	unresolved := security.NewTenantContext(context.Background(), "tenant-a")
+	_, err = repo.Find(unresolved, criteria)
+	require.NoError(t, err)
+	_, err = repo.Find(unresolved, criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 6, next.finds)
+}
+
+func TestRepositoryCaching_KeyCollisions(t *testing.T) {
+	ctx := context.Background()
+	items := []persistencetest.Item{{ID: "g", Name: "generation"}, {ID: "1_ref", Name: "reference"}}
+	repo, _, cache := newRepositoryCachingTest(t, items)
+
+	for _, item := range items {
+		got, err := repo.Get(ctx, item.ID)
+		require.NoError(t, err)
+		assert.Equal(t, item.Name, got.Name)
+	}
+	require.NoError(t, repo.Invalidate(ctx, "g"))
+	generation, err := cache.Get(ctx, "items:g")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), generation)
+	got, err := repo.Get(ctx, "1_ref")
+	require.NoError(t, err)
+	assert.Equal(t, "reference", got.Name)
+}
+
+func TestRepositoryCaching_ConcurrentInvalidation(t *testing.T) {
+	ctx := context.Background()
+	repo, next, _ := newRepositoryCachingTest(t, persistencetest.NewItems())
+	criteria := persistence.Criteria{LogicalOperator: data.LogicalOperatorAnd}
+
+	// an item is mutated after the page was queried but before it is stored
+	next.onFind = func() {
+		next.onFind = nil
+		require.NoError(t, repo.Invalidate(ctx, "1"))
+	}
+	_, err := repo.Find(ctx, criteria)
+	require.NoError(t, err)
+	_, err = repo.Find(ctx, criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.finds)
+	_, err = repo.Find(ctx, criteria)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.finds)
+
+	// an item is mutated after it was loaded but before it is stored
+	next.onGet = func() {
+		next.onGet = nil
+		require.NoError(t, repo.Invalidate(ctx, "1"))
+	}
+	_, err = repo.Get(ctx, "1")
+	require.NoError(t, err)
+	_, err = repo.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.gets)
+	_, err = repo.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.gets)
+}
+
+func TestRepositoryCaching_Transaction(t *testing.T) {
+	repo, next, cache := newRepositoryCachingTest(t, persistencetest.NewItems())
+	factory, mock := newTransactionContextFactory(t)
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	item, err := repo.Get(context.Background(), "1")
+	require.NoError(t, err)
+	txCtx, err := factory.NewContext(context.Background())
+	require.NoError(t, err)
+	// reads within transactions bypass the cache
+	_, err = repo.Get(txCtx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.gets)
+
+	// invalidation is deferred until commit
+	item.Age = 20
+	require.NoError(t, repo.Save(txCtx, item))
+	_, err = cache.Get(context.Background(), "items:e:0::1")
+	require.NoError(t, err)
+	require.NoError(t, persistence.CloseTransaction(txCtx, nil))
+	_, err = cache.Get(context.Background(), "items:e:0::1")
+	assert.True(t, errors.Is(err, caching.ErrCacheMiss))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCaching_PageRefs(t *testing.T) {
+	ctx := context.Background()
+	repo, next, cache := newRepositoryCachingTest(t, persistencetest.NewItems())
+	repo.MaxPageRefs = 2
+	newCriteria := func(age int) persistence.Criteria {
+		return persistence.Criteria{
+			Filters: []persistence.CriteriaFilter{
+				{Field: "age", Operator: data.OperatorGreaterThan, Value: []any{age}},
+			},
+			LogicalOperator: data.LogicalOperatorAnd,
+		}
+	}
+
+	// references are not duplicated by refreshed pages
+	criteriaHash, err := hashing.NewHashString(newCriteria(0))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = repo.Find(ctx, newCriteria(0))
+		require.NoError(t, err)
+		require.NoError(t, cache.Delete(ctx, "items:p:0::"+criteriaHash))
+	}
+	assert.Equal(t, 3, next.finds)
+	refs, err := cache.List(ctx, "items:r:1")
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+	ttl, err := cache.TTL(ctx, "items:r:1")
+	require.NoError(t, err)
+	assert.True(t, ttl > persistence.RepositoryCachingDefaultPageTTL &&
+		ttl <= persistence.RepositoryCachingDefaultItemTTL)
+
+	// entries of trimmed references are evicted
+	for _, age := range []int{0, 1, 2} {
+		_, err = repo.Find(ctx, newCriteria(age))
+		require.NoError(t, err)
+	}
+	refs, err = cache.List(ctx, "items:r:1")
+	require.NoError(t, err)
+	assert.Len(t, refs, 2)
+	finds := next.finds
+	_, err = repo.Find(ctx, newCriteria(2))
+	require.NoError(t, err)
+	assert.Equal(t, finds, next.finds)
+	_, err = repo.Find(ctx, newCriteria(0))
+	require.NoError(t, err)
+	assert.Equal(t, finds+1, next.finds)
+}
@@ -0,0 +1,449 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+// RepositoryMemory an in-memory PagingRepository, aimed for testing.
+//
+// Criteria are evaluated over struct fields tagged with `persistence:"name"` (embedded structs included),
+// where name is the storage name found in CriteriaFields. Evaluation mimics QueryBuilderSQL using
+// PostgreSQL semantics: comparisons against nil values never match (except null checks), LIKE patterns are
+// case-sensitive and nil values are sorted last on ascending order (first on descending order).
+// Items implementing SoftDeletable are excluded once deleted unless Criteria.IncludeDeleted is set.
+type RepositoryMemory[K comparable, T any] struct {
+	Fields CriteriaFields
+	Codec  data.PageTokenCodec
+
+	keyFunc func(T) K
+	mu      sync.RWMutex
+	keys    []K
+	items   map[K]T
+}
+
+var _ Repository[string, any] = (*RepositoryMemory[string, any])(nil)
+
+// NewRepositoryMemory allocates a RepositoryMemory instance. keyFunc retrieves the unique key of an item.
+func NewRepositoryMemory[K comparable, T any](fields CriteriaFields, codec data.PageTokenCodec,
+	keyFunc func(T) K) *RepositoryMemory[K, T] {
+	return &RepositoryMemory[K, T]{
+		Fields:  fields,
+		Codec:   codec,
+		keyFunc: keyFunc,
+		items:   map[K]T{},
+	}
+}
+
+// Save stores items, replacing existing ones with the same key.
+func (r *RepositoryMemory[K, T]) Save(_ context.Context, items ...T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, item := range items {
+		key := r.keyFunc(item)
+		if _, ok := r.items[key]; !ok {
+			r.keys = append(r.keys, key)
+		}
+		r.items[key] = item
+	}
+	return nil
+}
+
+// Get retrieves the item with the given key.
+func (r *RepositoryMemory[K, T]) Get(_ context.Context, key K) (T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	item, ok := r.items[key]
+	if !ok {
+		var zero T
+		return zero, systemerror.NewResourceNotFound[T](fmt.Sprint(key))
+	}
+	return item, nil
+}
+
+// Delete removes the item with the given key.
+func (r *RepositoryMemory[K, T]) Delete(_ context.Context, key K) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.items[key]; !ok {
+		return systemerror.NewResourceNotFound[T](fmt.Sprint(key))
+	}
+	delete(r.items, key)
+	for i, k := range r.keys {
+		if k == key {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *RepositoryMemory[K, T]) Find(_ context.Context, criteria Criteria) (data.Page[T], error) {
+	offset, err := ReadOffsetPageToken(r.Codec, criteria)
+	if err != nil {
+		return data.Page[T]{}, err
+	}
+
+	r.mu.RLock()
+	matches := make([]T, 0, len(r.keys))
+	for _, key := range r.keys {
+		item := r.items[key]
+		ok, errMatch := r.matches(item, criteria)
+		if errMatch != nil {
+			r.mu.RUnlock()
+			return data.Page[T]{}, errMatch
+		} else if ok {
+			matches = append(matches, item)
+		}
+	}
+	r.mu.RUnlock()
+
+	if criteria.Ordering.Field != "" {
+		if err = r.sort(matches, criteria.Ordering); err != nil {
+			return data.Page[T]{}, err
+		}
+	}
+
+	total := len(matches)
+	end := int64(total)
+	if criteria.PageSize > 0 {
+		end = min(offset+criteria.PageSize, end)
+	}
+	items := make([]T, 0, max(end-offset, 0))
+	if offset < end {
+		items = append(items, matches[offset:end]...)
+	}
+	return NewOffsetPage(r.Codec, criteria, items, offset, total)
+}
+
+func (r *RepositoryMemory[K, T]) matches(item T, criteria Criteria) (bool, error) {
+	if deletable, ok := any(item).(SoftDeletable); ok && !criteria.IncludeDeleted && deletable.IsDeleted() {
+		return false, nil
+	}
+
+	isOr := criteria.LogicalOperator == data.LogicalOperatorOr
+	for _, filter := range criteria.Filters {
+		value, err := r.fieldValue(item, filter.Field)
+		if err != nil {
+			return false, err
+		}
+		ok, err := evalFilterMemory(value, filter)
+		if err != nil {
+			return false, err
+		}
+		if isOr && ok {
+			return true, nil
+		} else if !isOr && !ok {
+			return false, nil
+		}
+	}
+	return !isOr || len(criteria.Filters) == 0, nil
+}
+
+func (r *RepositoryMemory[K, T]) sort(items []T, ordering CriteriaOrdering) error {
+	values := make(map[int]any, len(items))
+	for i, item := range items {
+		value, err := r.fieldValue(item, ordering.Field)
+		if err != nil {
+			return err
+		}
+		values[i] = value
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	desc := ordering.OrderType == data.OrderTypeDescending
+	var errCmp error
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := values[indexes[i]], values[indexes[j]]
+		if a == nil || b == nil {
+			// nulls last on ascending order, first on descending order
+			return (a == nil) == desc && (a == nil) != (b == nil)
+		}
+		cmp, err := compareValuesMemory(a, b)
+		if err != nil {
+			errCmp = err
+			return false
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	if errCmp != nil {
+		return errCmp
+	}
+
+	sorted := make([]T, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+// fieldValue retrieves the value of the struct field tagged with the storage name of criteriaField.
+// Nil pointers are returned as nil, other pointers are dereferenced.
+func (r *RepositoryMemory[K, T]) fieldValue(item T, criteriaField string) (any, error) {
+	name, ok := r.Fields[criteriaField]
+	if !ok {
+		return nil, systemerror.NewArgumentNotOneOf(criteriaField, sortedKeys(r.Fields)...)
+	}
+	value, ok := findTaggedFieldMemory(reflect.ValueOf(item), name)
+	if !ok {
+		return nil, fmt.Errorf("persistence: field '%s' not found in %T", name, item)
+	}
+	return indirectValueMemory(value), nil
+}
+
+func findTaggedFieldMemory(value reflect.Value, name string) (reflect.Value, bool) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if tag, _, _ := strings.Cut(field.Tag.Get("persistence"), ","); tag == name {
+			return value.Field(i), true
+		}
+		if field.Anonymous {
+			if found, ok := findTaggedFieldMemory(value.Field(i), name); ok {
+				return found, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func indirectValueMemory(value reflect.Value) any {
+	if !value.IsValid() {
+		return nil
+	}
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	return value.Interface()
+}
+
+func evalFilterMemory(value any, filter CriteriaFilter) (bool, error) {
+	switch filter.Operator {
+	case data.OperatorIsNull, data.OperatorNotExists:
+		return value == nil, nil
+	case data.OperatorIsNotNull, data.OperatorExists:
+		return value != nil, nil
+	case data.OperatorBetween, data.OperatorNotBetween:
+		if len(filter.Value) != 2 {
+			return false, systemerror.NewArgumentOutOfRange(filter.Field, 2, 2)
+		} else if value == nil {
+			return false, nil
+		}
+		lower, err := compareValuesMemory(value, filter.Value[0])
+		if err != nil {
+			return false, err
+		}
+		upper, err := compareValuesMemory(value, filter.Value[1])
+		if err != nil {
+			return false, err
+		}
+		between := lower >= 0 && upper <= 0
+		return between == (filter.Operator == data.OperatorBetween), nil
+	case data.OperatorIn, data.OperatorNotIn:
+		if len(filter.Value) == 0 {
+			return false, systemerror.NewArgumentOutOfRangeSingle(filter.Field, "min", 1)
+		} else if value == nil {
+			return false, nil
+		}
+		found := false
+		for _, v := range filter.Value {
+			if v == nil {
+				// SQL NULL never equals a value, turning NOT IN into unknown
+				if filter.Operator == data.OperatorNotIn {
+					return false, nil
+				}
+				continue
+			}
+			cmp, err := compareValuesMemory(value, v)
+			if err != nil {
+				return false, err
+			} else if cmp == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (filter.Operator == data.OperatorIn), nil
+	}
+
+	if _, ok := comparisonOperatorsSQL[filter.Operator]; !ok {
+		return false, ErrUnsupportedOperator
+	} else if len(filter.Value) != 1 {
+		return false, systemerror.NewArgumentOutOfRange(filter.Field, 1, 1)
+	} else if value == nil || filter.Value[0] == nil {
+		return false, nil
+	}
+
+	switch filter.Operator {
+	case data.OperatorLike, data.OperatorNotLike:
+		ok, err := matchLikeMemory(value, filter.Value[0])
+		if err != nil {
+			return false, err
+		}
+		return ok == (filter.Operator == data.OperatorLike), nil
+	}
+
+	cmp, err := compareValuesMemory(value, filter.Value[0])
+	if err != nil {
+		return false, err
+	}
+	switch filter.Operator {
+	case data.OperatorEquals:
+		return cmp == 0, nil
+	case data.OperatorNotEquals:
+		return cmp != 0, nil
+	case data.OperatorGreaterThan:
+		return cmp > 0, nil
+	case data.OperatorGreaterThanEquals:
+		return cmp >= 0, nil
+	case data.OperatorLessThan:
+		return cmp < 0, nil
+	default:
+		return cmp <= 0, nil
+	}
+}
+
+func matchLikeMemory(value, pattern any) (bool, error) {
+	str, ok := value.(string)
+	if !ok {
+		return false, ErrIncomparableValues
+	}
+	patternStr, ok := pattern.(string)
+	if !ok {
+		return false, ErrIncomparableValues
+	}
+
+	buf := strings.Builder{}
+	buf.WriteString("^")
+	escaped := false
+	for _, c := range patternStr {
+		switch {
+		case escaped:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '%':
+			buf.WriteString("(?s:.*)")
+		case c == '_':
+			buf.WriteString("(?s:.)")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	exp, err := regexp.Compile(buf.String())
+	if err != nil {
+		return false, err
+	}
+	return exp.MatchString(str), nil
+}
+
+// compareValuesMemory compares a and b, returning -1, 0 or +1. Numbers of different kinds are compared by
+// value.
+func compareValuesMemory(a, b any) (int, error) {
+	a, b = indirectValueMemory(reflect.ValueOf(a)), indirectValueMemory(reflect.ValueOf(b))
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isIntMemory(va) && isIntMemory(vb):
+		return cmpOrderedMemory(va.Int(), vb.Int()), nil
+	case isUintMemory(va) && isUintMemory(vb):
+		return cmpOrderedMemory(va.Uint(), vb.Uint()), nil
+	case isNumberMemory(va) && isNumberMemory(vb):
+		return cmpOrderedMemory(toFloatMemory(va), toFloatMemory(vb)), nil
+	case va.Kind() == reflect.String && vb.Kind() == reflect.String:
+		return strings.Compare(va.String(), vb.String()), nil
+	case va.Kind() == reflect.Bool && vb.Kind() == reflect.Bool:
+		return cmpOrderedMemory(boolToIntMemory(va.Bool()), boolToIntMemory(vb.Bool())), nil
+	}
+
+	ta, okA := a.(time.Time)
+	tb, okB := b.(time.Time)
+	if okA && okB {
+		return ta.Compare(tb), nil
+	}
+	return 0, ErrIncomparableValues
+}
+
+type orderedMemory interface {
+	~int64 | ~uint64 | ~float64
+}
+
+func cmpOrderedMemory[T orderedMemory](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isIntMemory(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUintMemory(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNumberMemory(v reflect.Value) bool {
+	return isIntMemory(v) || isUintMemory(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloatMemory(v reflect.Value) float64 {
+	switch {
+	case isIntMemory(v):
+		return float64(v.Int())
+	case isUintMemory(v):
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func boolToIntMemory(v bool) int64 {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/data/persistence/persistencetest"
+)
+
+func TestRepositoryMemory(t *testing.T) {
+	persistencetest.RunPagingRepositorySuite(t,
+		func(t *testing.T, items []persistencetest.Item) persistence.PagingRepository[persistencetest.Item] {
+			codec, err := data.NewPageTokenCodec(data.ConfigPageToken{SecretKey: data.PageTokenDefaultEncryptionKey})
+			require.NoError(t, err)
+			repo := persistence.NewRepositoryMemory(persistencetest.ItemFields, codec,
+				func(item persistencetest.Item) string {
+					return item.ID
+				})
+			require.NoError(t, repo.Save(context.Background(), items...))
+			return repo
+		})
+}
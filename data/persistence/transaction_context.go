@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/neutrinocorp/geck/observability/logging"
 )
 
 type transactionContextType string
@@ -13,20 +17,230 @@ const transactionContextKey transactionContextType = "persistence.tx"
 type Transaction interface {
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
+	// AfterCommit registers a TransactionCallback executed once the transaction was committed successfully.
+	AfterCommit(cb TransactionCallback) error
+	// AfterRollback registers a TransactionCallback executed once the transaction was rolled back.
+	AfterRollback(cb TransactionCallback) error
+}
+
+// TransactionCallback a routine executed after a Transaction has been completed (e.g. publish an event,
+// invalidate a cache entry).
+//
+// The given context.Context holds no transaction, so any persistence operation will run outside it.
+type TransactionCallback func(ctx context.Context) error
+
+// transactionCallbacksSQL callbacks registered within a transaction scope.
+type transactionCallbacksSQL struct {
+	mu            sync.Mutex
+	afterCommit   []TransactionCallback
+	afterRollback []TransactionCallback
+}
+
+func (c *transactionCallbacksSQL) addAfterCommit(cb TransactionCallback) {
+	c.mu.Lock()
+	c.afterCommit = append(c.afterCommit, cb)
+	c.mu.Unlock()
+}
+
+func (c *transactionCallbacksSQL) addAfterRollback(cb TransactionCallback) {
+	c.mu.Lock()
+	c.afterRollback = append(c.afterRollback, cb)
+	c.mu.Unlock()
+}
+
+// drain removes and returns every registered callback. A nil c holds no callbacks.
+func (c *transactionCallbacksSQL) drain() (afterCommit, afterRollback []TransactionCallback) {
+	if c == nil {
+		return nil, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	afterCommit, afterRollback = c.afterCommit, c.afterRollback
+	c.afterCommit, c.afterRollback = nil, nil
+	return
+}
+
+// transactionStateSQL state shared by every scope (participating and nested) of a physical sql.Tx.
+type transactionStateSQL struct {
+	mu           sync.Mutex
+	rollbackOnly bool
+	savepointSeq uint64
+	logger       logging.Logger
 }
 
+// runCallbacks executes the given callbacks with a context.Context detached from the transaction. Every
+// callback is executed, errors are logged and then returned as a single error.
+func (s *transactionStateSQL) runCallbacks(ctx context.Context, callbacks []TransactionCallback) error {
+	if len(callbacks) == 0 {
+		return nil
+	}
+	ctx = context.WithValue(ctx, transactionContextKey, nil)
+	errs := make([]error, 0, len(callbacks))
+	for _, cb := range callbacks {
+		if err := cb(ctx); err != nil {
+			if s.logger != nil {
+				s.logger.WithError(err).WriteWithCtx(ctx, "transaction callback failed")
+			}
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.Join(append([]error{ErrTxCallback}, errs...)...)
+}
+
+func (s *transactionStateSQL) markRollbackOnly() {
+	s.mu.Lock()
+	s.rollbackOnly = true
+	s.mu.Unlock()
+}
+
+func (s *transactionStateSQL) isRollbackOnly() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rollbackOnly
+}
+
+func (s *transactionStateSQL) nextSavepoint() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.savepointSeq++
+	return "geck_sp_" + strconv.FormatUint(s.savepointSeq, 10)
+}
+
+// TransactionSQL is the Transaction implementation for database/sql.
+//
+// A TransactionSQL might represent a whole physical transaction, a scope participating in an existing
+// transaction (PropagationRequired) or a scope bounded by a SQL SAVEPOINT (PropagationNested).
 type TransactionSQL struct {
 	Tx *sql.Tx
+	// Savepoint name of the SQL SAVEPOINT bounding this scope. Empty if scope is not nested.
+	Savepoint string
+	// Participant indicates this scope joined an existing transaction, hence, it does not own it.
+	Participant bool
+
+	state     *transactionStateSQL
+	callbacks *transactionCallbacksSQL
+	// parentCallbacks callbacks of the enclosing scope. Nested scopes hand over their callbacks to it once
+	// their savepoint is released.
+	parentCallbacks *transactionCallbacksSQL
 }
 
 var _ Transaction = (*TransactionSQL)(nil)
 
-func (t TransactionSQL) Commit(_ context.Context) error {
-	return t.Tx.Commit()
+// NewTransactionSQL allocates a TransactionSQL owning the given sql.Tx. The logger is used to
+// report TransactionCallback failures.
+func NewTransactionSQL(tx *sql.Tx, logger logging.Logger) TransactionSQL {
+	return TransactionSQL{
+		Tx: tx,
+		state: &transactionStateSQL{
+			logger: logger,
+		},
+		callbacks: &transactionCallbacksSQL{},
+	}
+}
+
+// isInitialized indicates whether t was allocated by NewTransactionSQL (or a TransactionContextFactorySQL).
+// Scopes built by hand hold no state, so they cannot register callbacks nor mark the transaction as
+// rollback-only.
+func (t TransactionSQL) isInitialized() bool {
+	return t.state != nil && t.callbacks != nil
 }
 
-func (t TransactionSQL) Rollback(_ context.Context) error {
-	return t.Tx.Rollback()
+// AfterCommit registers a TransactionCallback executed once the physical transaction was committed successfully.
+//
+// Callbacks registered within a nested scope are discarded if its savepoint is rolled back. Returns
+// ErrTxNotInitialized if t was not allocated by NewTransactionSQL, as cb would be silently dropped otherwise.
+func (t TransactionSQL) AfterCommit(cb TransactionCallback) error {
+	if !t.isInitialized() {
+		return ErrTxNotInitialized
+	}
+	t.callbacks.addAfterCommit(cb)
+	return nil
+}
+
+// AfterRollback registers a TransactionCallback executed once the transaction scope was rolled back.
+//
+// Callbacks registered within a nested scope are executed as soon as its savepoint is rolled back. Returns
+// ErrTxNotInitialized if t was not allocated by NewTransactionSQL, as cb would be silently dropped otherwise.
+func (t TransactionSQL) AfterRollback(cb TransactionCallback) error {
+	if !t.isInitialized() {
+		return ErrTxNotInitialized
+	}
+	t.callbacks.addAfterRollback(cb)
+	return nil
+}
+
+// Commit commits the transaction scope.
+//
+// Participating scopes are no-op as the owner scope commits the transaction. Nested scopes release their
+// savepoint. Owner scopes roll back the transaction and return ErrTxRollbackOnly if a participating scope
+// was rolled back.
+//
+// Registered callbacks are executed after the physical transaction completes. Their errors are joined
+// with ErrTxCallback and returned.
+func (t TransactionSQL) Commit(ctx context.Context) error {
+	switch {
+	case t.Participant:
+		return nil
+	case t.Savepoint != "":
+		if !t.isInitialized() {
+			return ErrTxNotInitialized
+		}
+		if _, err := t.Tx.ExecContext(ctx, "RELEASE SAVEPOINT "+t.Savepoint); err != nil {
+			return err
+		}
+		afterCommit, afterRollback := t.callbacks.drain()
+		if t.parentCallbacks != nil {
+			for _, cb := range afterCommit {
+				t.parentCallbacks.addAfterCommit(cb)
+			}
+			for _, cb := range afterRollback {
+				t.parentCallbacks.addAfterRollback(cb)
+			}
+		}
+		return nil
+	case t.state != nil && t.state.isRollbackOnly():
+		err := errors.Join(ErrTxRollbackOnly, t.Tx.Rollback())
+		_, afterRollback := t.callbacks.drain()
+		return errors.Join(err, t.state.runCallbacks(ctx, afterRollback))
+	}
+
+	// scopes not initialized hold no callbacks, hence, runCallbacks never reaches a nil state
+	afterCommit, afterRollback := t.callbacks.drain()
+	if err := t.Tx.Commit(); err != nil {
+		return errors.Join(err, t.state.runCallbacks(ctx, afterRollback))
+	}
+	return t.state.runCallbacks(ctx, afterCommit)
+}
+
+// Rollback rolls back the transaction scope.
+//
+// Participating scopes mark the whole transaction as rollback-only. Nested scopes roll back to their savepoint,
+// leaving work done before the savepoint untouched.
+func (t TransactionSQL) Rollback(ctx context.Context) error {
+	switch {
+	case t.Participant:
+		if !t.isInitialized() {
+			return ErrTxNotInitialized
+		}
+		t.state.markRollbackOnly()
+		return nil
+	case t.Savepoint != "":
+		if !t.isInitialized() {
+			return ErrTxNotInitialized
+		}
+		if _, err := t.Tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+t.Savepoint); err != nil {
+			return err
+		}
+	default:
+		if err := t.Tx.Rollback(); err != nil {
+			return err
+		}
+	}
+	_, afterRollback := t.callbacks.drain()
+	return t.state.runCallbacks(ctx, afterRollback)
 }
 
 func GetTxFromContext(ctx context.Context) (Transaction, error) {
@@ -37,6 +251,30 @@ func GetTxFromContext(ctx context.Context) (Transaction, error) {
 	return tx, nil
 }
 
+// RegisterAfterCommit registers a TransactionCallback into the Transaction found in the given context.Context.
+// The callback is executed once the transaction was committed successfully.
+//
+// Returns ErrTxContextNotFound if no transaction was found.
+func RegisterAfterCommit(ctx context.Context, cb TransactionCallback) error {
+	tx, err := GetTxFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return tx.AfterCommit(cb)
+}
+
+// RegisterAfterRollback registers a TransactionCallback into the Transaction found in the given context.Context.
+// The callback is executed once the transaction was rolled back.
+//
+// Returns ErrTxContextNotFound if no transaction was found.
+func RegisterAfterRollback(ctx context.Context, cb TransactionCallback) error {
+	tx, err := GetTxFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return tx.AfterRollback(cb)
+}
+
 func CloseTransaction(ctx context.Context, srcErr error) error {
 	tx, err := GetTxFromContext(ctx)
 	if err != nil {
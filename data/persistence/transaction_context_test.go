@@ -0,0 +1,257 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/security"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+func newTransactionContextFactory(t *testing.T) (persistence.TransactionContextFactorySQL, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return persistence.NewTransactionContextFactorySQLWithLogger(db, persistence.ConfigTransactionManagerSQL{},
+		logging.NewStdLogger(log.New(io.Discard, "", 0))), mock
+}
+
+func TestTransactionContextFactorySQL_NewContextWithPropagation(t *testing.T) {
+	errScope := errors.New("scope failed")
+	tests := []struct {
+		name  string
+		setup func(mock sqlmock.Sqlmock)
+		exec  func(t *testing.T, factory persistence.TransactionContextFactorySQL) error
+		err   error
+	}{
+		{
+			name: "nested rollback reverts to savepoint",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT geck_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT geck_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			exec: func(t *testing.T, factory persistence.TransactionContextFactorySQL) error {
+				ctx, err := factory.NewContext(context.Background())
+				require.NoError(t, err)
+				innerCtx, err := factory.NewContextWithPropagation(ctx, persistence.PropagationNested)
+				require.NoError(t, err)
+				assert.ErrorIs(t, persistence.CloseTransaction(innerCtx, errScope), errScope)
+				return persistence.CloseTransaction(ctx, nil)
+			},
+		},
+		{
+			name: "nested commit releases savepoint",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT geck_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("SAVEPOINT geck_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("RELEASE SAVEPOINT geck_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("RELEASE SAVEPOINT geck_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			exec: func(t *testing.T, factory persistence.TransactionContextFactorySQL) error {
+				ctx, err := factory.NewContext(context.Background())
+				require.NoError(t, err)
+				nestedCtx, err := factory.NewContextWithPropagation(ctx, persistence.PropagationNested)
+				require.NoError(t, err)
+				innerCtx, err := factory.NewContextWithPropagation(nestedCtx, persistence.PropagationNested)
+				require.NoError(t, err)
+				require.NoError(t, persistence.CloseTransaction(innerCtx, nil))
+				require.NoError(t, persistence.CloseTransaction(nestedCtx, nil))
+				return persistence.CloseTransaction(ctx, nil)
+			},
+		},
+		{
+			name: "required participant rollback marks transaction as rollback-only",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			exec: func(t *testing.T, factory persistence.TransactionContextFactorySQL) error {
+				ctx, err := factory.NewContext(context.Background())
+				require.NoError(t, err)
+				innerCtx, err := factory.NewContextWithPropagation(ctx, persistence.PropagationRequired)
+				require.NoError(t, err)
+				assert.ErrorIs(t, persistence.CloseTransaction(innerCtx, errScope), errScope)
+				return persistence.CloseTransaction(ctx, nil)
+			},
+			err: persistence.ErrTxRollbackOnly,
+		},
+		{
+			name: "requires new starts an independent transaction",
+			setup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				mock.ExpectCommit()
+			},
+			exec: func(t *testing.T, factory persistence.TransactionContextFactorySQL) error {
+				ctx, err := factory.NewContext(context.Background())
+				require.NoError(t, err)
+				innerCtx, err := factory.NewContextWithPropagation(ctx, persistence.PropagationRequiresNew)
+				require.NoError(t, err)
+				assert.ErrorIs(t, persistence.CloseTransaction(innerCtx, errScope), errScope)
+				return persistence.CloseTransaction(ctx, nil)
+			},
+		},
+		{
+			name:  "unsupported propagation",
+			setup: func(mock sqlmock.Sqlmock) {},
+			exec: func(t *testing.T, factory persistence.TransactionContextFactorySQL) error {
+				_, err := factory.NewContextWithPropagation(context.Background(), "MANDATORY")
+				return err
+			},
+			err: persistence.ErrTxPropagationNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory, mock := newTransactionContextFactory(t)
+			tt.setup(mock)
+			err := tt.exec(t, factory)
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTransactionSQL_Callbacks(t *testing.T) {
+	factory, mock := newTransactionContextFactory(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT geck_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT geck_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT geck_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT geck_sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	calls := make([]string, 0)
+	register := func(name string, err error) persistence.TransactionCallback {
+		return func(ctx context.Context) error {
+			_, errTx := persistence.GetTxFromContext(ctx)
+			assert.ErrorIs(t, errTx, persistence.ErrTxContextNotFound)
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	ctx, err := factory.NewContext(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, persistence.RegisterAfterCommit(ctx, register("root_commit", nil)))
+
+	rolledBackCtx, err := factory.NewContextWithPropagation(ctx, persistence.PropagationNested)
+	require.NoError(t, err)
+	require.NoError(t, persistence.RegisterAfterCommit(rolledBackCtx, register("nested_commit_discarded", nil)))
+	require.NoError(t, persistence.RegisterAfterRollback(rolledBackCtx, register("nested_rollback", nil)))
+	assert.Error(t, persistence.CloseTransaction(rolledBackCtx, errors.New("scope failed")))
+	assert.Equal(t, []string{"nested_rollback"}, calls)
+
+	releasedCtx, err := factory.NewContextWithPropagation(ctx, persistence.PropagationNested)
+	require.NoError(t, err)
+	errCallback := errors.New("publish failed")
+	require.NoError(t, persistence.RegisterAfterCommit(releasedCtx, register("nested_commit", errCallback)))
+	require.NoError(t, persistence.CloseTransaction(releasedCtx, nil))
+	assert.Equal(t, []string{"nested_rollback"}, calls)
+
+	err = persistence.CloseTransaction(ctx, nil)
+	assert.ErrorIs(t, err, persistence.ErrTxCallback)
+	assert.ErrorIs(t, err, errCallback)
+	assert.Equal(t, []string{"nested_rollback", "root_commit", "nested_commit"}, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.ErrorIs(t, persistence.RegisterAfterCommit(context.Background(), register("none", nil)),
+		persistence.ErrTxContextNotFound)
+}
+
+func TestTransactionSQL_NotInitialized(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+	sqlTx, err := db.Begin()
+	require.NoError(t, err)
+
+	tx := persistence.TransactionSQL{Tx: sqlTx}
+	noop := func(context.Context) error {
+		return nil
+	}
+	assert.ErrorIs(t, tx.AfterCommit(noop), persistence.ErrTxNotInitialized)
+	assert.ErrorIs(t, tx.AfterRollback(noop), persistence.ErrTxNotInitialized)
+	participant := persistence.TransactionSQL{Tx: sqlTx, Participant: true}
+	assert.ErrorIs(t, participant.Rollback(context.Background()), persistence.ErrTxNotInitialized)
+
+	require.NoError(t, tx.Commit(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+type tenantMemberPrincipalTest struct {
+	security.PrincipalTemplate
+	tenants []string
+}
+
+func (p tenantMemberPrincipalTest) IsTenantMember(tenantID string) bool {
+	return slices.Contains(p.tenants, tenantID)
+}
+
+func TestTransactionContextFactorySQL_Tenant(t *testing.T) {
+	factory, mock := newTransactionContextFactory(t)
+	factory.Config.TenantSessionVariable = "app.tenant_id"
+
+	_, err := factory.NewContext(context.Background())
+	assert.ErrorIs(t, err, systemerror.ErrPermissionDenied)
+	// tenants requested by unauthenticated callers are not trusted
+	_, err = factory.NewContext(security.NewTenantContext(context.Background(), "tenant-a"))
+	assert.ErrorIs(t, err, systemerror.ErrPermissionDenied)
+	// neither are tenants requested by principals with no tenant membership
+	_, err = factory.NewContext(security.NewTenantContext(context.WithValue(context.Background(),
+		security.PrincipalContextKey, security.PrincipalTemplate{Identifier: "user-1"}), "tenant-a"))
+	assert.ErrorIs(t, err, systemerror.ErrPermissionDenied)
+
+	memberCtx := context.WithValue(context.Background(), security.PrincipalContextKey,
+		tenantMemberPrincipalTest{PrincipalTemplate: security.PrincipalTemplate{Identifier: "operator-1"},
+			tenants: []string{"tenant-c"}})
+	_, err = factory.NewContext(security.NewTenantContext(memberCtx, "tenant-b"))
+	assert.ErrorIs(t, err, systemerror.ErrPermissionDenied)
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config").
+		WithArgs("app.tenant_id", "tenant-c").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	ctx, err := factory.NewContext(security.NewTenantContext(memberCtx, "tenant-c"))
+	require.NoError(t, err)
+	require.NoError(t, persistence.CloseTransaction(ctx, nil))
+
+	principalCtx := context.WithValue(context.Background(), security.PrincipalContextKey,
+		security.PrincipalTemplate{Identifier: "user-1", Tenant: "tenant-a"})
+	_, err = factory.NewContext(security.NewTenantContext(principalCtx, "tenant-b"))
+	assert.ErrorIs(t, err, systemerror.ErrPermissionDenied)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT set_config").
+		WithArgs("app.tenant_id", "tenant-a").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	ctx, err = factory.NewContext(security.NewTenantContext(principalCtx, "tenant-a"))
+	require.NoError(t, err)
+	require.NoError(t, persistence.CloseTransaction(ctx, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,30 +3,110 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"errors"
+
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/security"
 )
 
 type TransactionContextFactory interface {
+	// NewContext allocates a transactional context.Context using the default TransactionPropagation.
 	NewContext(parent context.Context) (context.Context, error)
+	// NewContextWithPropagation allocates a transactional context.Context using the given TransactionPropagation.
+	NewContextWithPropagation(parent context.Context, propagation TransactionPropagation) (context.Context, error)
 }
 
 type TransactionContextFactorySQL struct {
 	DB     ClientSQL
 	Config ConfigTransactionManagerSQL
+	Logger logging.Logger
 }
 
 var _ TransactionContextFactory = (*TransactionContextFactorySQL)(nil)
 
+// NewTransactionContextFactorySQL allocates a TransactionContextFactorySQL instance. Errors of transaction
+// callbacks are returned only, use NewTransactionContextFactorySQLWithLogger to log them.
 func NewTransactionContextFactorySQL(db ClientSQL, cfg ConfigTransactionManagerSQL) TransactionContextFactorySQL {
+	return NewTransactionContextFactorySQLWithLogger(db, cfg, nil)
+}
+
+// NewTransactionContextFactorySQLWithLogger allocates a TransactionContextFactorySQL instance logging errors
+// of transaction callbacks with logger.
+func NewTransactionContextFactorySQLWithLogger(db ClientSQL, cfg ConfigTransactionManagerSQL,
+	logger logging.Logger) TransactionContextFactorySQL {
 	return TransactionContextFactorySQL{
 		DB:     db,
 		Config: cfg,
+		Logger: logger,
 	}
 }
 
+// NewContext allocates a transactional context.Context using ConfigTransactionManagerSQL.Propagation.
+// Falls back to PropagationRequired if no propagation was configured.
 func (t TransactionContextFactorySQL) NewContext(parent context.Context) (context.Context, error) {
-	_, err := GetTxFromContext(parent)
-	if err == nil {
-		return parent, nil // re-use ctx
+	propagation := t.Config.Propagation
+	if propagation == "" {
+		propagation = PropagationRequired
+	}
+	return t.NewContextWithPropagation(parent, propagation)
+}
+
+// NewContextWithPropagation allocates a transactional context.Context using the given TransactionPropagation.
+//
+// Close the scope with CloseTransaction using the returned context.Context.
+func (t TransactionContextFactorySQL) NewContextWithPropagation(parent context.Context,
+	propagation TransactionPropagation) (context.Context, error) {
+	var parentTx TransactionSQL
+	txRaw, err := GetTxFromContext(parent)
+	hasParent := err == nil
+	if hasParent {
+		parentTx, hasParent = txRaw.(TransactionSQL)
+	}
+	if hasParent && propagation != PropagationRequiresNew && !parentTx.isInitialized() {
+		return nil, ErrTxNotInitialized
+	}
+
+	switch propagation {
+	case PropagationRequiresNew:
+		return t.newTxContext(parent)
+	case PropagationRequired:
+		if !hasParent {
+			return t.newTxContext(parent)
+		}
+		return context.WithValue(parent, transactionContextKey, TransactionSQL{
+			Tx:          parentTx.Tx,
+			Participant: true,
+			state:       parentTx.state,
+			callbacks:   parentTx.callbacks,
+		}), nil
+	case PropagationNested:
+		if !hasParent {
+			return t.newTxContext(parent)
+		}
+		state := parentTx.state
+		savepoint := state.nextSavepoint()
+		if _, err = parentTx.Tx.ExecContext(parent, "SAVEPOINT "+savepoint); err != nil {
+			return nil, err
+		}
+		return context.WithValue(parent, transactionContextKey, TransactionSQL{
+			Tx:              parentTx.Tx,
+			Savepoint:       savepoint,
+			state:           state,
+			callbacks:       &transactionCallbacksSQL{},
+			parentCallbacks: parentTx.callbacks,
+		}), nil
+	default:
+		return nil, ErrTxPropagationNotSupported
+	}
+}
+
+func (t TransactionContextFactorySQL) newTxContext(parent context.Context) (context.Context, error) {
+	var tenantID string
+	if t.Config.TenantSessionVariable != "" {
+		var err error
+		if tenantID, err = security.GetTenantFromContext(parent); err != nil {
+			return nil, err
+		}
 	}
 
 	tx, err := t.DB.BeginTx(parent, &sql.TxOptions{
@@ -36,5 +116,13 @@ func (t TransactionContextFactorySQL) NewContext(parent context.Context) (contex
 	if err != nil {
 		return nil, err
 	}
-	return context.WithValue(parent, transactionContextKey, TransactionSQL{Tx: tx}), nil
+	if tenantID != "" {
+		// is_local=true scopes the variable to this transaction (i.e. SET LOCAL), so pooled connections
+		// never leak a tenant.
+		if _, err = tx.ExecContext(parent, "SELECT set_config($1, $2, true)",
+			t.Config.TenantSessionVariable, tenantID); err != nil {
+			return nil, errors.Join(err, tx.Rollback())
+		}
+	}
+	return context.WithValue(parent, transactionContextKey, NewTransactionSQL(tx, t.Logger)), nil
 }
@@ -0,0 +1,20 @@
+package persistence
+
+// TransactionPropagation defines how a new transactional scope behaves when a transaction is already
+// present in the parent context.Context. Semantics are similar to Spring Framework's propagation modes.
+type TransactionPropagation string
+
+const (
+	// PropagationRequired joins the parent transaction if any. Otherwise, starts a new transaction.
+	//
+	// A participating scope does not commit the parent transaction. Rolling it back marks the whole
+	// transaction as rollback-only.
+	PropagationRequired TransactionPropagation = "REQUIRED"
+	// PropagationRequiresNew always starts a new, independent transaction, regardless of the parent transaction.
+	PropagationRequiresNew TransactionPropagation = "REQUIRES_NEW"
+	// PropagationNested creates a SQL SAVEPOINT within the parent transaction if any. Otherwise, starts a
+	// new transaction.
+	//
+	// Rolling back a nested scope only reverts the work done after its savepoint.
+	PropagationNested TransactionPropagation = "NESTED"
+)
@@ -0,0 +1,19 @@
+package idempotency
+
+import "time"
+
+// Config configuration structure for idempotency components.
+type Config struct {
+	// HeaderName name of the request header holding the idempotency key.
+	HeaderName string `env:"IDEMPOTENCY_HEADER_NAME" envDefault:"Idempotency-Key"`
+	// TTL time a Record is kept. Requests reusing an expired key are processed again.
+	TTL time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	// LockTTL maximum time a key stays locked by an in-progress request. Protects against crashed processes.
+	LockTTL time.Duration `env:"IDEMPOTENCY_LOCK_TTL" envDefault:"1m"`
+	// Methods request methods the idempotency check is applied to.
+	Methods []string `env:"IDEMPOTENCY_METHODS" envDefault:"POST,PATCH"`
+	// TableName name of the table used by StoreSQL.
+	TableName string `env:"IDEMPOTENCY_TABLE" envDefault:"idempotency_keys"`
+	// KeyPrefix prefix of the keys used by StoreCache.
+	KeyPrefix string `env:"IDEMPOTENCY_KEY_PREFIX" envDefault:"idempotency:"`
+}
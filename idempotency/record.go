@@ -0,0 +1,74 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+// RecordStatus the processing status of a Record.
+type RecordStatus string
+
+const (
+	// RecordStatusInProgress the original request is still being processed.
+	RecordStatusInProgress RecordStatus = "IN_PROGRESS"
+	// RecordStatusCompleted the original request was processed and its response stored.
+	RecordStatusCompleted RecordStatus = "COMPLETED"
+)
+
+// Record the state of an idempotency key along the stored response of its original request.
+type Record struct {
+	Key string `json:"key"`
+	// RequestHash hash of the original request, used to detect key reuse with different requests.
+	RequestHash string       `json:"request_hash"`
+	Status      RecordStatus `json:"status"`
+	// ResponseCode status code of the stored response.
+	ResponseCode int `json:"response_code,omitempty"`
+	// ResponseHeaders headers of the stored response.
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	// ResponseBody body of the stored response.
+	ResponseBody []byte    `json:"response_body,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// IsExpired indicates whether the record expired at the given time.
+func (r Record) IsExpired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Store persists idempotency Record(s).
+type Store interface {
+	// Lock atomically creates an in-progress Record for the key, expiring at Record.ExpiresAt.
+	//
+	// If a non-expired record already exists, it is returned along acquired = false.
+	Lock(ctx context.Context, record Record) (existing Record, acquired bool, err error)
+	// Complete stores the response of a locked Record.
+	Complete(ctx context.Context, record Record) error
+	// Unlock removes a locked Record, so the request can be retried.
+	Unlock(ctx context.Context, key string) error
+}
+
+var (
+	// ErrRecordNotFound no record was found for the key.
+	ErrRecordNotFound = errors.New("idempotency record not found")
+)
+
+// NewKeyMismatchError allocates a systemerror.SystemError with systemerror.StatusAborted. The key was
+// already used by a request with a different payload.
+func NewKeyMismatchError(key string) systemerror.SystemError {
+	return systemerror.NewAborted("IDEMPOTENCY_KEY_MISMATCH",
+		"idempotency key was already used by a different request", map[string]string{
+			"idempotency_key": key,
+		})
+}
+
+// NewKeyInProgressError allocates a systemerror.SystemError with systemerror.StatusAborted. A request using
+// the same key is still being processed.
+func NewKeyInProgressError(key string) systemerror.SystemError {
+	return systemerror.NewAborted("IDEMPOTENCY_KEY_IN_PROGRESS",
+		"a request with the same idempotency key is being processed", map[string]string{
+			"idempotency_key": key,
+		})
+}
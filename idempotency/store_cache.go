@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/neutrinocorp/geck/data/caching"
+)
+
+// storeCacheLockAttempts number of times Lock retries when the stored record changes in between operations.
+const storeCacheLockAttempts = 3
+
+// StoreCache is the caching.Cache implementation of Store. Records are encoded as JSON.
+//
+// Locking relies on caching.Cache.SetIfNotExists, so it is atomic across program instances sharing a
+// distributed caching.Cache (e.g. caching.CacheRedis).
+type StoreCache struct {
+	Config Config
+	Cache  caching.Cache
+}
+
+var _ Store = (*StoreCache)(nil)
+
+// NewStoreCache allocates a StoreCache instance.
+func NewStoreCache(cfg Config, cache caching.Cache) *StoreCache {
+	return &StoreCache{
+		Config: cfg,
+		Cache:  cache,
+	}
+}
+
+func (s *StoreCache) decode(encoded []byte) (Record, error) {
+	record := Record{}
+	if err := json.Unmarshal(encoded, &record); err != nil {
+		return Record{}, err
+	}
+	return record, nil
+}
+
+// encode returns the encoded record along its caching TTL. ExpiresAt is kept within the record too. Returns
+// expired = true if the record already expired.
+func (s *StoreCache) encode(record Record) (encoded []byte, ttl time.Duration, expired bool, err error) {
+	encoded, err = json.Marshal(record)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if !record.ExpiresAt.IsZero() {
+		if ttl = time.Until(record.ExpiresAt); ttl <= 0 {
+			return nil, 0, true, nil
+		}
+	}
+	return encoded, ttl, false, nil
+}
+
+func (s *StoreCache) set(ctx context.Context, record Record) error {
+	encoded, ttl, expired, err := s.encode(record)
+	if err != nil || expired {
+		return err
+	}
+	return s.Cache.SetWithTTL(ctx, s.Config.KeyPrefix+record.Key, encoded, ttl)
+}
+
+func (s *StoreCache) Lock(ctx context.Context, record Record) (Record, bool, error) {
+	record.Status = RecordStatusInProgress
+	encoded, ttl, expired, err := s.encode(record)
+	if err != nil {
+		return Record{}, false, err
+	} else if expired {
+		return record, true, nil
+	}
+
+	key := s.Config.KeyPrefix + record.Key
+	for attempt := 0; attempt < storeCacheLockAttempts; attempt++ {
+		acquired, err := s.Cache.SetIfNotExists(ctx, key, encoded, ttl)
+		if err != nil {
+			return Record{}, false, err
+		} else if acquired {
+			return record, true, nil
+		}
+
+		existingEncoded, err := s.Cache.Get(ctx, key)
+		if errors.Is(err, caching.ErrCacheMiss) {
+			// removed in between (e.g. unlocked), try again
+			continue
+		} else if err != nil {
+			return Record{}, false, err
+		}
+		existing, err := s.decode(existingEncoded)
+		if err != nil {
+			return Record{}, false, err
+		} else if !existing.IsExpired(time.Now()) {
+			return existing, false, nil
+		}
+
+		// take over a record which expired but was not evicted yet
+		swapped, err := s.Cache.CompareAndSwap(ctx, key, existingEncoded, encoded)
+		if err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+			return Record{}, false, err
+		} else if swapped {
+			return record, true, s.Cache.Touch(ctx, key, ttl)
+		}
+	}
+	return Record{}, false, NewKeyInProgressError(record.Key)
+}
+
+func (s *StoreCache) Complete(ctx context.Context, record Record) error {
+	record.Status = RecordStatusCompleted
+	return s.set(ctx, record)
+}
+
+func (s *StoreCache) Unlock(ctx context.Context, key string) error {
+	if err := s.Cache.Delete(ctx, s.Config.KeyPrefix+key); err != nil && !errors.Is(err, caching.ErrCacheMiss) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package idempotency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/idempotency"
+)
+
+func TestStoreCache_Lock(t *testing.T) {
+	server := miniredis.RunT(t)
+	newStore := func() *idempotency.StoreCache {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() {
+			_ = client.Close()
+		})
+		return idempotency.NewStoreCache(idempotency.Config{KeyPrefix: "idempotency:"},
+			caching.NewCacheRedis(client, caching.RedisConfig{}))
+	}
+	// instances sharing the same cache
+	storeA, storeB := newStore(), newStore()
+	ctx := context.Background()
+	record := idempotency.Record{Key: "key", RequestHash: "hash", ExpiresAt: time.Now().Add(time.Minute)}
+
+	locked, acquired, err := storeA.Lock(ctx, record)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, idempotency.RecordStatusInProgress, locked.Status)
+	existing, acquired, err := storeB.Lock(ctx, record)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "hash", existing.RequestHash)
+	assert.Greater(t, server.TTL("idempotency:key"), time.Duration(0))
+
+	require.NoError(t, storeA.Unlock(ctx, "key"))
+	_, acquired, err = storeB.Lock(ctx, record)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	locked.ResponseCode = 201
+	require.NoError(t, storeB.Complete(ctx, locked))
+	existing, acquired, err = storeA.Lock(ctx, record)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, idempotency.RecordStatusCompleted, existing.Status)
+	assert.Equal(t, 201, existing.ResponseCode)
+}
@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neutrinocorp/geck/data/persistence"
+)
+
+// StoreSQL is the persistence.ClientSQL implementation of Store. Locking relies on the table primary key,
+// so it is atomic across program instances.
+//
+// Statements use PostgreSQL syntax (see NewSchemaPostgres).
+type StoreSQL struct {
+	Config Config
+	Client persistence.ClientSQL
+}
+
+var _ Store = (*StoreSQL)(nil)
+
+// NewStoreSQL allocates a StoreSQL instance.
+func NewStoreSQL(cfg Config, client persistence.ClientSQL) StoreSQL {
+	return StoreSQL{
+		Config: cfg,
+		Client: client,
+	}
+}
+
+// NewSchemaPostgres returns the PostgreSQL statement creating the StoreSQL table. Add it to the schema migrations.
+func NewSchemaPostgres(tableName string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	idempotency_key VARCHAR(255) PRIMARY KEY,
+	request_hash VARCHAR(128) NOT NULL,
+	status VARCHAR(16) NOT NULL,
+	response_code INT,
+	response_headers TEXT,
+	response_body BYTEA,
+	expires_at TIMESTAMP NOT NULL
+);`, tableName)
+}
+
+func (s StoreSQL) Lock(ctx context.Context, record Record) (Record, bool, error) {
+	// takes over expired records, inserts otherwise
+	res, err := s.Client.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (idempotency_key, request_hash, status, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (idempotency_key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = EXCLUDED.status,
+response_code = NULL, response_headers = NULL, response_body = NULL, expires_at = EXCLUDED.expires_at
+WHERE %s.expires_at <= $5`, s.Config.TableName, s.Config.TableName),
+		record.Key, record.RequestHash, string(RecordStatusInProgress), record.ExpiresAt.UTC(), time.Now().UTC())
+	if err != nil {
+		return Record{}, false, err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return Record{}, false, err
+	} else if affected > 0 {
+		record.Status = RecordStatusInProgress
+		return record, true, nil
+	}
+
+	existing, err := s.get(persistence.NewReadYourWritesContext(ctx), record.Key)
+	if err != nil {
+		return Record{}, false, err
+	}
+	return existing, false, nil
+}
+
+func (s StoreSQL) get(ctx context.Context, key string) (Record, error) {
+	row := s.Client.QueryRowContext(ctx, fmt.Sprintf(`SELECT idempotency_key, request_hash, status, response_code,
+response_headers, response_body, expires_at FROM %s WHERE idempotency_key = $1`, s.Config.TableName), key)
+	var (
+		record       Record
+		status       string
+		responseCode sql.NullInt64
+		headers      sql.NullString
+	)
+	err := row.Scan(&record.Key, &record.RequestHash, &status, &responseCode, &headers, &record.ResponseBody,
+		&record.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Record{}, ErrRecordNotFound
+	} else if err != nil {
+		return Record{}, err
+	}
+	record.Status = RecordStatus(status)
+	record.ResponseCode = int(responseCode.Int64)
+	if headers.Valid && headers.String != "" {
+		if err = json.Unmarshal([]byte(headers.String), &record.ResponseHeaders); err != nil {
+			return Record{}, err
+		}
+	}
+	return record, nil
+}
+
+func (s StoreSQL) Complete(ctx context.Context, record Record) error {
+	headers, err := json.Marshal(record.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	_, err = s.Client.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET status = $1, response_code = $2,
+response_headers = $3, response_body = $4, expires_at = $5 WHERE idempotency_key = $6`, s.Config.TableName),
+		string(RecordStatusCompleted), record.ResponseCode, string(headers), record.ResponseBody,
+		record.ExpiresAt.UTC(), record.Key)
+	return err
+}
+
+func (s StoreSQL) Unlock(ctx context.Context, key string) error {
+	_, err := s.Client.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE idempotency_key = $1",
+		s.Config.TableName), key)
+	return err
+}
@@ -0,0 +1,30 @@
+package idempotencyfx
+
+import (
+	"github.com/caarlos0/env/v11"
+	"go.uber.org/fx"
+
+	"github.com/neutrinocorp/geck/idempotency"
+)
+
+// StoreCacheModule provides idempotency.Store backed by caching.Cache. Suitable for single instance programs.
+var StoreCacheModule = fx.Module("idempotency_store_cache",
+	fx.Provide(
+		env.ParseAs[idempotency.Config],
+		fx.Annotate(
+			idempotency.NewStoreCache,
+			fx.As(new(idempotency.Store)),
+		),
+	),
+)
+
+// StoreSQLModule provides idempotency.Store backed by persistence.ClientSQL.
+var StoreSQLModule = fx.Module("idempotency_store_sql",
+	fx.Provide(
+		env.ParseAs[idempotency.Config],
+		fx.Annotate(
+			idempotency.NewStoreSQL,
+			fx.As(new(idempotency.Store)),
+		),
+	),
+)
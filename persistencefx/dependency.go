@@ -0,0 +1,161 @@
+package persistencefx
+
+import (
+	"database/sql"
+
+	"github.com/caarlos0/env/v11"
+	"go.uber.org/fx"
+
+	"github.com/neutrinocorp/geck/actuator"
+	"github.com/neutrinocorp/geck/actuatorfx"
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/data/persistence/migration"
+	"github.com/neutrinocorp/geck/data/persistence/outbox"
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/observability/loggingfx"
+)
+
+// SQLModule provides a database/sql connection pool (*sql.DB) driven by persistence.ConfigClientSQL.
+//
+// Exposes persistence.ClientSQL as the composition of persistence.TransactionalClientSQL and
+// persistence.StatementLoggerClientSQL, persistence.TransactionContextFactory and registers
+// persistence.ActuatorSQL. persistence.ClientSQL is further wrapped by ReadReplicasSQLModule and
+// InstrumentedSQLModule when used.
+//
+// Database drivers must be registered (imported) by the application.
+var SQLModule = fx.Module("persistence_sql",
+	fx.Decorate(
+		loggingfx.DecorateLoggerWithModule("persistence.sql"),
+	),
+	fx.Provide(
+		env.ParseAs[persistence.ConfigClientSQL],
+		env.ParseAs[persistence.ConfigTransactionManagerSQL],
+		persistence.NewDB,
+		fx.Annotate(
+			persistence.NewStatementLoggerClientSQL,
+			fx.From(new(logging.Logger), new(*sql.DB)),
+		),
+		fx.Annotate(
+			persistence.NewTransactionContextFactorySQLWithLogger,
+			fx.From(new(persistence.StatementLoggerClientSQL)),
+			fx.As(fx.Self()),
+			fx.As(new(persistence.TransactionContextFactory)),
+		),
+		newClientSQL,
+		fx.Annotate(
+			persistence.NewActuatorSQL,
+			fx.From(new(persistence.StatementLoggerClientSQL)),
+			fx.As(new(actuator.Actuator)),
+			fx.ResultTags(`group:"actuators"`),
+		),
+	),
+)
+
+// ReadReplicasSQLModule wraps the persistence.ClientSQL of SQLModule with persistence.RoutingClientSQL,
+// sending read statements to the read replicas specified in persistence.ConfigRoutingClientSQL.
+//
+// No wrapping is applied if no read replica was specified.
+var ReadReplicasSQLModule = fx.Options(
+	fx.Provide(
+		env.ParseAs[persistence.ConfigRoutingClientSQL],
+	),
+)
+
+// InstrumentedSQLModule wraps the persistence.ClientSQL of SQLModule with persistence.InstrumentedClientSQL,
+// recording statement metrics into persistence.MetricsSQL and logging slow statements.
+//
+// Statements routed by ReadReplicasSQLModule are recorded too.
+var InstrumentedSQLModule = fx.Options(
+	fx.Provide(
+		env.ParseAs[persistence.ConfigInstrumentedClientSQL],
+		fx.Annotate(
+			persistence.NewMetricsSQL,
+			fx.As(fx.Self()),
+			fx.As(new(persistence.MetricsRecorderSQL)),
+		),
+	),
+)
+
+// MigrationModule provides migration.Migrator, applying pending schema migrations on application start
+// (see migration.Config), and registers migration.Actuator.
+//
+// Requires a migration.Source supplied by the application, e.g.:
+//
+//	//go:embed migrations/*.sql
+//	var migrationsFS embed.FS
+//
+//	fx.Supply(migration.Source{FS: migrationsFS, Directory: "migrations"})
+var MigrationModule = fx.Module("persistence_sql_migration",
+	fx.Decorate(
+		loggingfx.DecorateLoggerWithModule("persistence.sql.migration"),
+	),
+	fx.Provide(
+		env.ParseAs[migration.Config],
+		migration.NewMigrator,
+		actuatorfx.AsActuator(migration.NewActuator),
+	),
+	fx.Invoke(
+		func(*migration.Migrator) {},
+	),
+)
+
+// PageTokenModule provides data.PageTokenCodec driven by data.ConfigPageToken.
+var PageTokenModule = fx.Module("persistence_page_token",
+	fx.Provide(
+		env.ParseAs[data.ConfigPageToken],
+		data.NewPageTokenCodec,
+	),
+)
+
+// OutboxModule provides outbox.Outbox and starts outbox.Relay, dispatching enqueued events on background.
+//
+// Requires an identifier.Factory and an outbox.Publisher provided by the application.
+var OutboxModule = fx.Module("persistence_sql_outbox",
+	fx.Decorate(
+		loggingfx.DecorateLoggerWithModule("persistence.sql.outbox"),
+	),
+	fx.Provide(
+		env.ParseAs[outbox.Config],
+		outbox.NewOutbox,
+		outbox.NewRelay,
+	),
+	fx.Invoke(
+		func(*outbox.Relay) {},
+	),
+)
+
+type clientSQLParams struct {
+	fx.In
+
+	Lifecycle  fx.Lifecycle
+	Logger     logging.Logger
+	PoolConfig persistence.ConfigClientSQL
+	Factory    persistence.TransactionContextFactorySQL
+	Next       persistence.StatementLoggerClientSQL
+	// RoutingConfig provided by ReadReplicasSQLModule.
+	RoutingConfig persistence.ConfigRoutingClientSQL `optional:"true"`
+	// InstrumentedConfig and Recorder provided by InstrumentedSQLModule.
+	InstrumentedConfig persistence.ConfigInstrumentedClientSQL `optional:"true"`
+	Recorder           persistence.MetricsRecorderSQL          `optional:"true"`
+}
+
+// newClientSQL composes persistence.ClientSQL in a single place, so ReadReplicasSQLModule and
+// InstrumentedSQLModule can be used together without decorating persistence.ClientSQL twice.
+func newClientSQL(params clientSQLParams) (persistence.ClientSQL, error) {
+	var client persistence.ClientSQL = persistence.NewTransactionalClientSQL(params.Factory, params.Logger,
+		params.Next)
+	if len(params.RoutingConfig.ReplicaDSNs) > 0 {
+		routing, err := persistence.NewReplicatedClientSQL(params.Lifecycle, params.RoutingConfig, params.PoolConfig,
+			params.Logger.Module("persistence.sql.routing"), client)
+		if err != nil {
+			return nil, err
+		}
+		client = routing
+	}
+	if params.Recorder != nil {
+		client = persistence.NewInstrumentedClientSQL(params.InstrumentedConfig, params.Logger, params.Recorder,
+			client)
+	}
+	return client, nil
+}
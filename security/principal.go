@@ -16,9 +16,10 @@ type PrincipalTemplate struct {
 	Subject      string
 	User         string
 	AuthoritySet sets.Set[string]
+	Tenant       string
 }
 
-var _ Principal = (*PrincipalTemplate)(nil)
+var _ TenantPrincipal = (*PrincipalTemplate)(nil)
 
 func (b PrincipalTemplate) ID() string {
 	return b.Identifier
@@ -35,3 +36,7 @@ func (b PrincipalTemplate) Username() string {
 func (b PrincipalTemplate) Authorities() sets.Set[string] {
 	return b.AuthoritySet
 }
+
+func (b PrincipalTemplate) TenantID() string {
+	return b.Tenant
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/samber/lo"
 )
 
+// TenantClaimCognito custom attribute claim holding the tenant of a Cognito user.
+const TenantClaimCognito = "custom:tenant_id"
+
 type PrincipalFactoryCognito struct {
 }
 
@@ -35,6 +38,7 @@ func (p PrincipalFactoryCognito) convertToPrincipal(args *jwt.Token) (Principal,
 	}
 	sub, _ := claims.GetSubject()
 	username, _ := claims["username"].(string)
+	tenantID, _ := claims[TenantClaimCognito].(string)
 	scopesRaw, _ := claims["scope"].(string)
 	scopes := strings.Split(scopesRaw, " ")
 
@@ -50,5 +54,6 @@ func (p PrincipalFactoryCognito) convertToPrincipal(args *jwt.Token) (Principal,
 		Subject:      sub,
 		User:         username,
 		AuthoritySet: authoritySet,
+		Tenant:       tenantID,
 	}, nil
 }
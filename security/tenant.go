@@ -0,0 +1,60 @@
+package security
+
+import (
+	"context"
+
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+type TenantContextType string
+
+const TenantContextKey TenantContextType = "security.tenant"
+
+// TenantPrincipal a Principal bound to a tenant.
+type TenantPrincipal interface {
+	Principal
+	TenantID() string
+}
+
+// TenantMemberPrincipal a Principal allowed to act on behalf of several tenants (e.g. back-office operators),
+// selecting one per operation with NewTenantContext.
+type TenantMemberPrincipal interface {
+	Principal
+	// IsTenantMember indicates whether the principal is a member of tenantID.
+	IsTenantMember(tenantID string) bool
+}
+
+// NewTenantContext allocates a context.Context carrying tenantID.
+//
+// The tenant is requested by the caller, so it is not trusted by GetTenantFromContext unless the
+// authenticated Principal is a member of it.
+func NewTenantContext(parent context.Context, tenantID string) context.Context {
+	return context.WithValue(parent, TenantContextKey, tenantID)
+}
+
+// GetTenantFromContext retrieves the tenant of the current operation from the authenticated Principal.
+//
+// The tenant of a TenantPrincipal is returned; a tenant carried by NewTenantContext must match it. Otherwise,
+// the tenant carried by NewTenantContext is returned only if the principal is a TenantMemberPrincipal member
+// of it. Returns a permission denied error in any other case (e.g. no principal).
+func GetTenantFromContext(ctx context.Context) (string, error) {
+	tenantID, _ := ctx.Value(TenantContextKey).(string)
+	principal, err := GetPrincipalFromContext(ctx)
+	if err != nil {
+		return "", systemerror.NewPermissionDeniedMissingTenant()
+	}
+
+	if tenantPrincipal, ok := principal.(TenantPrincipal); ok && tenantPrincipal.TenantID() != "" {
+		if tenantID != "" && tenantID != tenantPrincipal.TenantID() {
+			return "", systemerror.NewPermissionDeniedInvalidTenant(principal.ID(), tenantID)
+		}
+		return tenantPrincipal.TenantID(), nil
+	}
+	memberPrincipal, ok := principal.(TenantMemberPrincipal)
+	if !ok || tenantID == "" {
+		return "", systemerror.NewPermissionDeniedMissingTenant()
+	} else if !memberPrincipal.IsTenantMember(tenantID) {
+		return "", systemerror.NewPermissionDeniedInvalidTenant(principal.ID(), tenantID)
+	}
+	return tenantID, nil
+}
@@ -0,0 +1,19 @@
+package systemerror
+
+import "errors"
+
+// ErrAborted the operation was aborted, typically due to a concurrency issue (e.g. conflicting requests).
+var ErrAborted = errors.New("aborted")
+
+// NewAborted allocates a SystemError with StatusAborted and ErrAborted.
+//
+// The operation was aborted.
+func NewAborted(reason, message string, metadata map[string]string) SystemError {
+	return SystemError{
+		ErrStatus:   StatusAborted,
+		ErrReason:   reason,
+		ErrMessage:  message,
+		ErrMetadata: metadata,
+		StaticError: ErrAborted,
+	}
+}
@@ -3,6 +3,7 @@ package systemerror
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -130,3 +131,20 @@ func NewInvalidNoPrefixArgument(argumentName, excludedPrefix string) SystemError
 func NewInvalidNoSuffixArgument(argumentName, excludedSuffix string) SystemError {
 	return newInvalidPrefixSuffix(argumentName, "no suffix", excludedSuffix)
 }
+
+// NewInvalidSyntaxArgument allocates a new SystemError using StatusInvalidArgument and ErrInvalidArgument.
+//
+// An argument expressed in a query language could not be parsed.
+// Attaches 'INVALID_SYNTAX' reason and the position (zero-based) where parsing failed.
+func NewInvalidSyntaxArgument(argumentName string, position int, detail string) SystemError {
+	return SystemError{
+		ErrStatus:  StatusInvalidArgument,
+		ErrReason:  "INVALID_SYNTAX",
+		ErrMessage: fmt.Sprintf("'%s' has an invalid syntax at position %d, %s", argumentName, position, detail),
+		ErrMetadata: map[string]string{
+			"position": strconv.Itoa(position),
+			"detail":   detail,
+		},
+		StaticError: ErrInvalidArgument,
+	}
+}
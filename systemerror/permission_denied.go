@@ -42,3 +42,33 @@ func NewPermissionDeniedInvalidOwner(principalID string, expOwnerID string) Syst
 		StaticError: ErrPermissionDenied,
 	}
 }
+
+// NewPermissionDeniedMissingTenant allocates a SystemError with StatusPermissionDenied and ErrPermissionDenied.
+//
+// No tenant was found for the operation.
+// Sets 'TENANT_NOT_FOUND' as reason.
+func NewPermissionDeniedMissingTenant() SystemError {
+	return SystemError{
+		ErrStatus:   StatusPermissionDenied,
+		ErrReason:   "TENANT_NOT_FOUND",
+		ErrMessage:  "tenant is required to perform this operation",
+		StaticError: ErrPermissionDenied,
+	}
+}
+
+// NewPermissionDeniedInvalidTenant allocates a SystemError with StatusPermissionDenied and ErrPermissionDenied.
+//
+// Requested tenant does not match the tenant of the principal.
+// Sets 'PRINCIPAL_NOT_TENANT_MEMBER' as reason.
+func NewPermissionDeniedInvalidTenant(principalID string, tenantID string) SystemError {
+	return SystemError{
+		ErrStatus:  StatusPermissionDenied,
+		ErrReason:  "PRINCIPAL_NOT_TENANT_MEMBER",
+		ErrMessage: "principal is not authorized to perform this operation",
+		ErrMetadata: map[string]string{
+			"principal": principalID,
+			"tenant":    tenantID,
+		},
+		StaticError: ErrPermissionDenied,
+	}
+}
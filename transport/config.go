@@ -10,6 +10,7 @@ type ConfigHTTP struct {
 	Address                 string   `env:"HTTP_SERVER_ADDRESS" envDefault:":8080"`
 	AuthenticationWhitelist []string `env:"HTTP_SERVER_AUTHN_WHITELIST" envDefault:"/healthz,/readiness"`
 	RequestIDTargetHeader   string   `env:"HTTP_REQ_ID_TARGET_HEADER" envDefault:"X-Request-ID"`
+	TenantHeader            string   `env:"HTTP_TENANT_HEADER" envDefault:"X-Tenant-ID"`
 
 	AuthenticationWhitelistSet sets.Set[string]
 }
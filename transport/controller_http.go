@@ -64,7 +64,7 @@ func (a ActuatorControllerHTTP) getLiveness(c echo.Context) error {
 
 func (a ActuatorControllerHTTP) getReadiness(c echo.Context) error {
 	state, err := a.Manager.Health(c.Request().Context())
-	if err != nil || state.Status != actuator.StatusUp {
+	if err != nil || !state.Status.IsAvailable() {
 		return c.NoContent(http.StatusServiceUnavailable)
 	}
 	return c.NoContent(http.StatusOK)
@@ -72,7 +72,7 @@ func (a ActuatorControllerHTTP) getReadiness(c echo.Context) error {
 
 func (a ActuatorControllerHTTP) getHealth(c echo.Context) error {
 	state, err := a.Manager.Health(c.Request().Context())
-	if err != nil || state.Status != actuator.StatusUp {
+	if err != nil || !state.Status.IsAvailable() {
 		a.Logger.WithError(err).Write("health check failed")
 		return c.JSON(http.StatusServiceUnavailable, Data{
 			Data: state,
@@ -0,0 +1,160 @@
+package transport
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+const (
+	// CriteriaDefaultPageSize default page size used by CriteriaBinderHTTP.
+	CriteriaDefaultPageSize int64 = 50
+	// CriteriaMaxPageSize maximum page size accepted by CriteriaBinderHTTP.
+	CriteriaMaxPageSize int64 = 250
+	// CriteriaMaxPageTokenLength maximum page token length accepted by CriteriaBinderHTTP.
+	CriteriaMaxPageTokenLength = 255
+)
+
+// CriteriaBinderHTTP binds HTTP query-string parameters into persistence.Criteria instances:
+//
+//   - filter: an AIP-160 filter expression (see ParseCriteriaFilter).
+//   - order_by: a field name followed by an optional direction, e.g. "name desc".
+//   - page_size: maximum number of items to fetch.
+//   - page_token: an opaque data.PageToken from a previous page.
+//   - show_deleted: includes soft deleted items if true.
+//
+// A non-positive DefaultPageSize or MaxPageSize falls back to CriteriaDefaultPageSize or CriteriaMaxPageSize,
+// respectively, so zero-value instances are usable.
+//
+// Fields are validated against the persistence.CriteriaFields allowlist. Every invalid parameter is
+// reported using a systemerror.SystemError with systemerror.StatusInvalidArgument, joined together.
+type CriteriaBinderHTTP struct {
+	Fields          persistence.CriteriaFields
+	DefaultPageSize int64
+	MaxPageSize     int64
+}
+
+// NewCriteriaBinderHTTP allocates a CriteriaBinderHTTP instance using CriteriaDefaultPageSize and
+// CriteriaMaxPageSize.
+func NewCriteriaBinderHTTP(fields persistence.CriteriaFields) CriteriaBinderHTTP {
+	return CriteriaBinderHTTP{
+		Fields:          fields,
+		DefaultPageSize: CriteriaDefaultPageSize,
+		MaxPageSize:     CriteriaMaxPageSize,
+	}
+}
+
+// Bind binds query-string parameters of the request into a persistence.Criteria.
+func (b CriteriaBinderHTTP) Bind(c echo.Context) (persistence.Criteria, error) {
+	return b.BindValues(c.QueryParams())
+}
+
+// BindValues binds values into a persistence.Criteria.
+func (b CriteriaBinderHTTP) BindValues(values url.Values) (persistence.Criteria, error) {
+	criteria := persistence.Criteria{
+		PageSize:        b.getDefaultPageSize(),
+		LogicalOperator: data.LogicalOperatorAnd,
+	}
+	errs := make([]error, 0)
+	if filter := values.Get("filter"); strings.TrimSpace(filter) != "" {
+		var err error
+		criteria.LogicalOperator, criteria.Filters, err = parseCriteriaFilter("filter", filter, b.Fields)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if orderBy := values.Get("order_by"); strings.TrimSpace(orderBy) != "" {
+		ordering, err := b.parseOrderBy(orderBy)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		criteria.Ordering = ordering
+	}
+	if pageSize := values.Get("page_size"); pageSize != "" {
+		size, err := strconv.ParseInt(pageSize, 10, 64)
+		if err != nil {
+			errs = append(errs, systemerror.NewInvalidFormatArgument("page_size", "integer"))
+		} else if maxPageSize := b.getMaxPageSize(); size < 1 || size > maxPageSize {
+			errs = append(errs, systemerror.NewInvalidArgument("page_size", strconv.FormatInt(size, 10),
+				"[1,"+strconv.FormatInt(maxPageSize, 10)+"]"))
+		}
+		criteria.PageSize = size
+	}
+	if pageToken := values.Get("page_token"); pageToken != "" {
+		if len(pageToken) > CriteriaMaxPageTokenLength {
+			errs = append(errs, systemerror.NewInvalidArgument("page_token", "length "+strconv.Itoa(len(pageToken)),
+				"length <= "+strconv.Itoa(CriteriaMaxPageTokenLength)))
+		}
+		criteria.PageToken = data.PageToken(pageToken)
+	}
+	if showDeleted := values.Get("show_deleted"); showDeleted != "" {
+		include, err := strconv.ParseBool(showDeleted)
+		if err != nil {
+			errs = append(errs, systemerror.NewInvalidFormatArgument("show_deleted", "boolean"))
+		}
+		criteria.IncludeDeleted = include
+	}
+	if len(errs) > 0 {
+		return persistence.Criteria{}, errors.Join(errs...)
+	}
+	return criteria, nil
+}
+
+func (b CriteriaBinderHTTP) getDefaultPageSize() int64 {
+	if b.DefaultPageSize <= 0 {
+		return CriteriaDefaultPageSize
+	}
+	return b.DefaultPageSize
+}
+
+func (b CriteriaBinderHTTP) getMaxPageSize() int64 {
+	if b.MaxPageSize <= 0 {
+		return CriteriaMaxPageSize
+	}
+	return b.MaxPageSize
+}
+
+func (b CriteriaBinderHTTP) parseOrderBy(orderBy string) (persistence.CriteriaOrdering, error) {
+	parts := strings.Fields(orderBy)
+	if len(parts) > 2 {
+		return persistence.CriteriaOrdering{}, systemerror.NewInvalidFormatArgument("order_by",
+			"field [asc|desc]")
+	}
+	if _, ok := b.Fields[parts[0]]; !ok {
+		return persistence.CriteriaOrdering{}, systemerror.NewArgumentNotOneOf("order_by."+parts[0],
+			sortedFieldNames(b.Fields)...)
+	}
+
+	ordering := persistence.CriteriaOrdering{
+		Field:     parts[0],
+		OrderType: data.OrderTypeAscending,
+	}
+	if len(parts) == 2 {
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+		case "desc":
+			ordering.OrderType = data.OrderTypeDescending
+		default:
+			return persistence.CriteriaOrdering{}, systemerror.NewArgumentNotOneOf("order_by.direction",
+				"asc", "desc")
+		}
+	}
+	return ordering, nil
+}
+
+func sortedFieldNames(fields persistence.CriteriaFields) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
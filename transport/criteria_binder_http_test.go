@@ -0,0 +1,172 @@
+package transport_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/systemerror"
+	"github.com/neutrinocorp/geck/transport"
+)
+
+var criteriaFieldsTest = persistence.CriteriaFields{
+	"age":        "age",
+	"name":       "display_name",
+	"status":     "status",
+	"created_at": "created_at",
+}
+
+func TestCriteriaBinderHTTP_BindValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		in         url.Values
+		want       persistence.Criteria
+		wantReason []string
+	}{
+		{
+			name: "defaults",
+			in:   url.Values{},
+			want: persistence.Criteria{PageSize: 50, LogicalOperator: data.LogicalOperatorAnd},
+		},
+		{
+			name: "full",
+			in: url.Values{
+				"filter":       {`age>18 AND status="ACTIVE" AND created_at >= "2024-01-01T00:00:00Z"`},
+				"order_by":     {"name desc"},
+				"page_size":    {"10"},
+				"page_token":   {"abc"},
+				"show_deleted": {"true"},
+			},
+			want: persistence.Criteria{
+				PageSize:  10,
+				PageToken: data.PageToken("abc"),
+				Ordering: persistence.CriteriaOrdering{
+					Field:     "name",
+					OrderType: data.OrderTypeDescending,
+				},
+				LogicalOperator: data.LogicalOperatorAnd,
+				Filters: []persistence.CriteriaFilter{
+					{Field: "age", Operator: data.OperatorGreaterThan, Value: []any{int64(18)}},
+					{Field: "status", Operator: data.OperatorEquals, Value: []any{"ACTIVE"}},
+					{Field: "created_at", Operator: data.OperatorGreaterThanEquals,
+						Value: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}},
+				},
+				IncludeDeleted: true,
+			},
+		},
+		{
+			name: "operators",
+			in: url.Values{
+				"filter": {`name = "jo*" OR name != "a_b*" OR name = "x\*" OR age:* OR status = null OR ` +
+					`status != null OR age IN (1, 2.5) OR status NOT IN (ACTIVE, "DISABLED") OR age <= -1`},
+			},
+			want: persistence.Criteria{
+				PageSize:        50,
+				LogicalOperator: data.LogicalOperatorOr,
+				Filters: []persistence.CriteriaFilter{
+					{Field: "name", Operator: data.OperatorLike, Value: []any{"jo%"}},
+					{Field: "name", Operator: data.OperatorNotLike, Value: []any{`a\_b%`}},
+					{Field: "name", Operator: data.OperatorEquals, Value: []any{"x*"}},
+					{Field: "age", Operator: data.OperatorExists},
+					{Field: "status", Operator: data.OperatorIsNull},
+					{Field: "status", Operator: data.OperatorIsNotNull},
+					{Field: "age", Operator: data.OperatorIn, Value: []any{int64(1), 2.5}},
+					{Field: "status", Operator: data.OperatorNotIn, Value: []any{"ACTIVE", "DISABLED"}},
+					{Field: "age", Operator: data.OperatorLessThanEquals, Value: []any{int64(-1)}},
+				},
+			},
+		},
+		{
+			name: "implicit and",
+			in:   url.Values{"filter": {`age>18 status=ACTIVE`}},
+			want: persistence.Criteria{
+				PageSize:        50,
+				LogicalOperator: data.LogicalOperatorAnd,
+				Filters: []persistence.CriteriaFilter{
+					{Field: "age", Operator: data.OperatorGreaterThan, Value: []any{int64(18)}},
+					{Field: "status", Operator: data.OperatorEquals, Value: []any{"ACTIVE"}},
+				},
+			},
+		},
+		{
+			name: "invalid parameters",
+			in: url.Values{
+				"filter":       {`password = "x"`},
+				"order_by":     {"name sideways"},
+				"page_size":    {"1000"},
+				"show_deleted": {"maybe"},
+			},
+			wantReason: []string{"NOT_ONE_OF", "NOT_ONE_OF", "INVALID_ARGUMENT", "INVALID_FORMAT"},
+		},
+		{
+			name:       "mixed logical operators",
+			in:         url.Values{"filter": {`age>18 AND age<30 OR status=ACTIVE`}},
+			wantReason: []string{"INVALID_SYNTAX"},
+		},
+		{
+			name:       "unterminated string",
+			in:         url.Values{"filter": {`status = "ACTIVE`}},
+			wantReason: []string{"INVALID_SYNTAX"},
+		},
+		{
+			name:       "missing value",
+			in:         url.Values{"filter": {`age >`}},
+			wantReason: []string{"INVALID_SYNTAX"},
+		},
+		{
+			name:       "null ordering",
+			in:         url.Values{"filter": {`age > null`}},
+			wantReason: []string{"INVALID_SYNTAX"},
+		},
+	}
+	binder := transport.NewCriteriaBinderHTTP(criteriaFieldsTest)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := binder.BindValues(tt.in)
+			if len(tt.wantReason) == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				return
+			}
+
+			require.ErrorIs(t, err, systemerror.ErrInvalidArgument)
+			errs := []error{err}
+			if container, ok := err.(systemerror.Container); ok {
+				errs = container.Unwrap()
+			}
+			reasons := make([]string, 0, len(errs))
+			for _, e := range errs {
+				var sysErr systemerror.Error
+				require.ErrorAs(t, e, &sysErr)
+				assert.Equal(t, systemerror.StatusInvalidArgument, sysErr.Status())
+				reasons = append(reasons, sysErr.Reason())
+			}
+			assert.Equal(t, tt.wantReason, reasons)
+		})
+	}
+}
+
+func TestCriteriaBinderHTTP_ZeroValue(t *testing.T) {
+	binder := transport.CriteriaBinderHTTP{Fields: criteriaFieldsTest}
+
+	got, err := binder.BindValues(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, transport.CriteriaDefaultPageSize, got.PageSize)
+	got, err = binder.BindValues(url.Values{"page_size": {"250"}})
+	require.NoError(t, err)
+	assert.Equal(t, transport.CriteriaMaxPageSize, got.PageSize)
+	_, err = binder.BindValues(url.Values{"page_size": {"251"}})
+	assert.ErrorIs(t, err, systemerror.ErrInvalidArgument)
+}
+
+func TestParseCriteriaFilter_Position(t *testing.T) {
+	_, _, err := transport.ParseCriteriaFilter(`age > 18 AND %`, criteriaFieldsTest)
+	var sysErr systemerror.Error
+	require.ErrorAs(t, err, &sysErr)
+	assert.Equal(t, "13", sysErr.Metadata()["position"])
+}
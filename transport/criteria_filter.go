@@ -0,0 +1,371 @@
+package transport
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/neutrinocorp/geck/data"
+	"github.com/neutrinocorp/geck/data/persistence"
+	"github.com/neutrinocorp/geck/systemerror"
+)
+
+type filterTokenKind uint8
+
+const (
+	filterTokenEOF filterTokenKind = iota
+	filterTokenIdent
+	filterTokenString
+	filterTokenNumber
+	filterTokenOperator
+	filterTokenLParen
+	filterTokenRParen
+	filterTokenComma
+	filterTokenStar
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+type filterParser struct {
+	argumentName string
+	fields       persistence.CriteriaFields
+	tokens       []filterToken
+	pos          int
+}
+
+// ParseCriteriaFilter parses an AIP-160 (https://google.aip.dev/160) filter expression into
+// persistence.CriteriaFilter instances, validating fields against the given allowlist.
+//
+// Supported grammar:
+//
+//   - Comparisons: field = value, !=, <, <=, >, >=.
+//   - Wildcards: strings containing * use LIKE semantics (e.g. name = "foo*").
+//   - Presence: field:* (exists), field = null, field != null.
+//   - Membership: field IN (a, b), field NOT IN (a, b).
+//   - Conjunctions: AND or OR (not both, as persistence.Criteria has a single data.LogicalOperator).
+//     Whitespace-separated comparisons are implicitly joined with AND.
+//
+// Values are quoted strings, numbers, true, false, null or bare words (read as strings). Quoted strings in
+// RFC 3339 format are read as time.Time.
+func ParseCriteriaFilter(filter string, fields persistence.CriteriaFields) (data.LogicalOperator,
+	[]persistence.CriteriaFilter, error) {
+	return parseCriteriaFilter("filter", filter, fields)
+}
+
+func parseCriteriaFilter(argumentName, filter string, fields persistence.CriteriaFields) (data.LogicalOperator,
+	[]persistence.CriteriaFilter, error) {
+	tokens, err := lexCriteriaFilter(argumentName, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	parser := filterParser{
+		argumentName: argumentName,
+		fields:       fields,
+		tokens:       tokens,
+	}
+	return parser.parse()
+}
+
+func lexCriteriaFilter(argumentName, filter string) ([]filterToken, error) {
+	tokens := make([]filterToken, 0, 8)
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: filterTokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: filterTokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, filterToken{kind: filterTokenComma, text: ",", pos: i})
+			i++
+		case c == '*':
+			tokens = append(tokens, filterToken{kind: filterTokenStar, text: "*", pos: i})
+			i++
+		case c == '=' || c == ':':
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: string(c), pos: i})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			start := i
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				i++
+			} else if c == '!' {
+				return nil, systemerror.NewInvalidSyntaxArgument(argumentName, start, "expected '!='")
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenOperator, text: string(runes[start:i]), pos: start})
+		case c == '"' || c == '\'':
+			start := i
+			buf := strings.Builder{}
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					// keeps escaped wildcards escaped, so they can be told apart from * wildcards
+					if runes[i+1] == '*' {
+						buf.WriteRune('\\')
+					}
+					buf.WriteRune(runes[i+1])
+					i += 2
+					continue
+				} else if runes[i] == c {
+					closed = true
+					i++
+					break
+				}
+				buf.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, systemerror.NewInvalidSyntaxArgument(argumentName, start, "unterminated string")
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenString, text: buf.String(), pos: start})
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || strings.ContainsRune(".eE+-", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: filterTokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			return nil, systemerror.NewInvalidSyntaxArgument(argumentName, i,
+				"unexpected character '"+string(c)+"'")
+		}
+	}
+	return append(tokens, filterToken{kind: filterTokenEOF, pos: len(runes)}), nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	token := p.tokens[p.pos]
+	if token.kind != filterTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *filterParser) newSyntaxError(token filterToken, detail string) error {
+	return systemerror.NewInvalidSyntaxArgument(p.argumentName, token.pos, detail)
+}
+
+func isKeywordFilter(token filterToken, keyword string) bool {
+	return token.kind == filterTokenIdent && token.text == keyword
+}
+
+func (p *filterParser) parse() (data.LogicalOperator, []persistence.CriteriaFilter, error) {
+	var operator data.LogicalOperator
+	filters := make([]persistence.CriteriaFilter, 0, 4)
+	for {
+		filter, err := p.parseComparison()
+		if err != nil {
+			return 0, nil, err
+		}
+		filters = append(filters, filter)
+
+		token := p.peek()
+		if token.kind == filterTokenEOF {
+			break
+		}
+
+		current := data.LogicalOperatorAnd
+		switch {
+		case isKeywordFilter(token, "AND"):
+			p.next()
+		case isKeywordFilter(token, "OR"):
+			current = data.LogicalOperatorOr
+			p.next()
+		case token.kind != filterTokenIdent:
+			return 0, nil, p.newSyntaxError(token, "expected AND, OR or a comparison")
+		}
+		if operator != 0 && operator != current {
+			return 0, nil, p.newSyntaxError(token, "cannot mix AND and OR operators")
+		}
+		operator = current
+	}
+	if operator == 0 {
+		operator = data.LogicalOperatorAnd
+	}
+	return operator, filters, nil
+}
+
+func (p *filterParser) parseComparison() (persistence.CriteriaFilter, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != filterTokenIdent || isKeywordFilter(fieldToken, "AND") ||
+		isKeywordFilter(fieldToken, "OR") || isKeywordFilter(fieldToken, "NOT") {
+		return persistence.CriteriaFilter{}, p.newSyntaxError(fieldToken, "expected field name")
+	}
+	if _, ok := p.fields[fieldToken.text]; !ok {
+		return persistence.CriteriaFilter{}, systemerror.NewArgumentNotOneOf(p.argumentName+"."+fieldToken.text,
+			sortedFieldNames(p.fields)...)
+	}
+	filter := persistence.CriteriaFilter{Field: fieldToken.text}
+
+	opToken := p.next()
+	switch {
+	case isKeywordFilter(opToken, "IN"):
+		filter.Operator = data.OperatorIn
+		return p.parseList(filter)
+	case isKeywordFilter(opToken, "NOT"):
+		if inToken := p.next(); !isKeywordFilter(inToken, "IN") {
+			return persistence.CriteriaFilter{}, p.newSyntaxError(inToken, "expected IN")
+		}
+		filter.Operator = data.OperatorNotIn
+		return p.parseList(filter)
+	case opToken.kind != filterTokenOperator:
+		return persistence.CriteriaFilter{}, p.newSyntaxError(opToken, "expected comparison operator")
+	case opToken.text == ":":
+		if starToken := p.next(); starToken.kind != filterTokenStar {
+			return persistence.CriteriaFilter{}, p.newSyntaxError(starToken, "expected '*'")
+		}
+		filter.Operator = data.OperatorExists
+		return filter, nil
+	}
+
+	valueToken := p.peek()
+	value, err := p.parseValue()
+	if err != nil {
+		return persistence.CriteriaFilter{}, err
+	}
+
+	switch opToken.text {
+	case "=", "!=":
+		isEquals := opToken.text == "="
+		if value == nil {
+			filter.Operator = data.OperatorIsNotNull
+			if isEquals {
+				filter.Operator = data.OperatorIsNull
+			}
+			return filter, nil
+		}
+		if pattern, ok := newLikePatternFilter(valueToken, value); ok {
+			filter.Operator = data.OperatorNotLike
+			if isEquals {
+				filter.Operator = data.OperatorLike
+			}
+			filter.Value = []any{pattern}
+			return filter, nil
+		} else if str, isStr := value.(string); isStr && valueToken.kind == filterTokenString {
+			value = strings.ReplaceAll(str, `\*`, "*")
+		}
+		filter.Operator = data.OperatorNotEquals
+		if isEquals {
+			filter.Operator = data.OperatorEquals
+		}
+	case "<":
+		filter.Operator = data.OperatorLessThan
+	case "<=":
+		filter.Operator = data.OperatorLessThanEquals
+	case ">":
+		filter.Operator = data.OperatorGreaterThan
+	case ">=":
+		filter.Operator = data.OperatorGreaterThanEquals
+	}
+	if value == nil {
+		return persistence.CriteriaFilter{}, p.newSyntaxError(valueToken, "null is only comparable using = or !=")
+	}
+	filter.Value = []any{value}
+	return filter, nil
+}
+
+func (p *filterParser) parseList(filter persistence.CriteriaFilter) (persistence.CriteriaFilter, error) {
+	if token := p.next(); token.kind != filterTokenLParen {
+		return persistence.CriteriaFilter{}, p.newSyntaxError(token, "expected '('")
+	}
+	for {
+		valueToken := p.peek()
+		value, err := p.parseValue()
+		if err != nil {
+			return persistence.CriteriaFilter{}, err
+		} else if value == nil {
+			return persistence.CriteriaFilter{}, p.newSyntaxError(valueToken, "null is not allowed in lists")
+		}
+		filter.Value = append(filter.Value, value)
+
+		token := p.next()
+		if token.kind == filterTokenRParen {
+			return filter, nil
+		} else if token.kind != filterTokenComma {
+			return persistence.CriteriaFilter{}, p.newSyntaxError(token, "expected ',' or ')'")
+		}
+	}
+}
+
+func (p *filterParser) parseValue() (any, error) {
+	token := p.next()
+	switch token.kind {
+	case filterTokenString:
+		if t, err := time.Parse(time.RFC3339Nano, token.text); err == nil {
+			return t, nil
+		}
+		return token.text, nil
+	case filterTokenNumber:
+		if i, err := strconv.ParseInt(token.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, p.newSyntaxError(token, "invalid number '"+token.text+"'")
+		}
+		return f, nil
+	case filterTokenIdent:
+		switch strings.ToLower(token.text) {
+		case "null":
+			return nil, nil
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return token.text, nil
+	default:
+		return nil, p.newSyntaxError(token, "expected value")
+	}
+}
+
+// newLikePatternFilter converts a string containing unescaped * wildcards into a LIKE pattern, escaping
+// LIKE special characters.
+func newLikePatternFilter(token filterToken, value any) (string, bool) {
+	str, ok := value.(string)
+	if !ok || token.kind != filterTokenString {
+		return "", false
+	}
+
+	buf := strings.Builder{}
+	hasWildcard := false
+	runes := []rune(str)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '\\' && i+1 < len(runes) && runes[i+1] == '*':
+			buf.WriteRune('*')
+			i++
+		case c == '*':
+			buf.WriteRune('%')
+			hasWildcard = true
+		case c == '%' || c == '_' || c == '\\':
+			buf.WriteRune('\\')
+			buf.WriteRune(c)
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	return buf.String(), hasWildcard
+}
@@ -56,6 +56,27 @@ func NewTraceIDEcho(params TraceIDEchoParams) echo.MiddlewareFunc {
 	}
 }
 
+// NewTenantEcho injects the tenant found in ConfigHTTP.TenantHeader into each request using
+// security.NewTenantContext.
+//
+// Tenants are not enforced here as middleware ordering is not guaranteed (e.g. principal might not be
+// available yet). Instead, consumers enforce them using security.GetTenantFromContext, which only accepts
+// headers matching the tenant of an authenticated security.TenantPrincipal or a tenant the authenticated
+// security.TenantMemberPrincipal is member of.
+func NewTenantEcho(cfg ConfigHTTP) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tenantID := c.Request().Header.Get(cfg.TenantHeader)
+			if tenantID == "" {
+				return next(c)
+			}
+			ctx := security.NewTenantContext(c.Request().Context(), tenantID)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
 type DefaultEchoMiddlewareParams struct {
 	fx.In
 
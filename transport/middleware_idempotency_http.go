@@ -0,0 +1,161 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/emirpasic/gods/v2/sets/hashset"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/fx"
+
+	"github.com/neutrinocorp/geck/idempotency"
+	"github.com/neutrinocorp/geck/internal/hashing"
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/security"
+)
+
+// IdempotentReplayedHeader header set on responses replayed from an idempotency.Record.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// headers bound to the transport encoding of the original response, hence, not replayed.
+var idempotencySkippedHeaders = map[string]struct{}{
+	echo.HeaderContentEncoding: {},
+	echo.HeaderContentLength:   {},
+	echo.HeaderVary:            {},
+}
+
+type idempotentRequest struct {
+	Method    string
+	Path      string
+	Principal string
+	Body      []byte
+}
+
+type responseRecorderHTTP struct {
+	http.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (r responseRecorderHTTP) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the original http.ResponseWriter, used by http.ResponseController.
+func (r responseRecorderHTTP) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+type IdempotencyEchoParams struct {
+	fx.In
+
+	Config idempotency.Config
+	Store  idempotency.Store
+	Logger logging.Logger
+}
+
+// NewIdempotencyEcho allocates an Echo middleware deduplicating requests carrying an idempotency key
+// (idempotency.Config.HeaderName).
+//
+// The first request locks the key and its response is stored once processed, so duplicates get the same
+// response replayed. Requests reusing a key with a different payload (method, path, principal and body hashed
+// with hashing.NewHashString) or while the original request is in progress are rejected with
+// systemerror.StatusAborted. Failed requests (returned error or 5xx status) release the key, so they can be retried.
+//
+// Requests are bound to the ID of the authenticated security.Principal rather than their credentials, so
+// retries with refreshed tokens are still replayed. Hence, the middleware must run after authentication
+// (e.g. NewEchoJWTAuthenticator); unauthenticated requests are bound to no principal.
+func NewIdempotencyEcho(params IdempotencyEchoParams) echo.MiddlewareFunc {
+	methods := hashset.New(params.Config.Methods...)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := req.Header.Get(params.Config.HeaderName)
+			if key == "" || !methods.Contains(req.Method) {
+				return next(c)
+			}
+
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			principalID := ""
+			if principal, errPrincipal := security.GetPrincipalFromContext(req.Context()); errPrincipal == nil {
+				principalID = principal.ID()
+			}
+			requestHash, err := hashing.NewHashString(idempotentRequest{
+				Method:    req.Method,
+				Path:      req.URL.Path,
+				Principal: principalID,
+				Body:      body,
+			})
+			if err != nil {
+				return err
+			}
+
+			ctx := req.Context()
+			existing, acquired, err := params.Store.Lock(ctx, idempotency.Record{
+				Key:         key,
+				RequestHash: requestHash,
+				ExpiresAt:   time.Now().Add(params.Config.LockTTL),
+			})
+			if err != nil {
+				return err
+			} else if !acquired {
+				return replayIdempotentResponse(c, key, requestHash, existing)
+			}
+
+			recorder := responseRecorderHTTP{
+				ResponseWriter: c.Response().Writer,
+				body:           bytes.NewBuffer(nil),
+			}
+			c.Response().Writer = recorder
+			err = next(c)
+			c.Response().Writer = recorder.ResponseWriter
+			if err != nil || c.Response().Status >= http.StatusInternalServerError {
+				if errUnlock := params.Store.Unlock(context.WithoutCancel(ctx), key); errUnlock != nil {
+					params.Logger.WithError(errUnlock).WriteWithCtx(ctx, "failed to unlock idempotency key")
+				}
+				return err
+			}
+
+			headers := make(map[string][]string, len(c.Response().Header()))
+			for name, values := range c.Response().Header() {
+				if _, skip := idempotencySkippedHeaders[name]; !skip {
+					headers[name] = values
+				}
+			}
+			if errComplete := params.Store.Complete(context.WithoutCancel(ctx), idempotency.Record{
+				Key:             key,
+				RequestHash:     requestHash,
+				ResponseCode:    c.Response().Status,
+				ResponseHeaders: headers,
+				ResponseBody:    recorder.body.Bytes(),
+				ExpiresAt:       time.Now().Add(params.Config.TTL),
+			}); errComplete != nil {
+				params.Logger.WithError(errComplete).WriteWithCtx(ctx, "failed to store idempotent response")
+			}
+			return nil
+		}
+	}
+}
+
+func replayIdempotentResponse(c echo.Context, key, requestHash string, record idempotency.Record) error {
+	if record.RequestHash != requestHash {
+		return idempotency.NewKeyMismatchError(key)
+	} else if record.Status != idempotency.RecordStatusCompleted {
+		return idempotency.NewKeyInProgressError(key)
+	}
+
+	for name, values := range record.ResponseHeaders {
+		c.Response().Header()[name] = values
+	}
+	c.Response().Header().Set(IdempotentReplayedHeader, "true")
+	c.Response().WriteHeader(record.ResponseCode)
+	_, err := c.Response().Write(record.ResponseBody)
+	return err
+}
@@ -0,0 +1,116 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/neutrinocorp/geck/data/caching"
+	"github.com/neutrinocorp/geck/idempotency"
+	"github.com/neutrinocorp/geck/observability/logging"
+	"github.com/neutrinocorp/geck/security"
+	"github.com/neutrinocorp/geck/systemerror"
+	"github.com/neutrinocorp/geck/transport"
+)
+
+func TestNewIdempotencyEcho(t *testing.T) {
+	db, err := bigcache.New(context.Background(), bigcache.DefaultConfig(time.Minute))
+	require.NoError(t, err)
+	defer db.Close()
+	cfg := idempotency.Config{
+		HeaderName: "Idempotency-Key",
+		TTL:        time.Minute,
+		LockTTL:    time.Minute,
+		Methods:    []string{http.MethodPost},
+		KeyPrefix:  "idempotency:",
+	}
+	store := idempotency.NewStoreCache(cfg, caching.NewCacheEmbedded(db))
+
+	e := echo.New()
+	e.HTTPErrorHandler = transport.HandleEchoError
+	// authenticates requests, principals are identified by the X-User header whatever their token
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if user := c.Request().Header.Get("X-User"); user != "" {
+				c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(),
+					security.PrincipalContextKey, security.PrincipalTemplate{Identifier: user})))
+			}
+			return next(c)
+		}
+	})
+	e.Use(transport.NewIdempotencyEcho(transport.IdempotencyEchoParams{
+		Config: cfg,
+		Store:  store,
+		Logger: logging.NewStdLogger(log.New(io.Discard, "", 0)),
+	}))
+	calls := 0
+	e.POST("/orders", func(c echo.Context) error {
+		calls++
+		body, _ := io.ReadAll(c.Request().Body)
+		if string(body) == "fail" {
+			return errors.New("failed")
+		}
+		return c.JSON(http.StatusCreated, map[string]any{"call": calls})
+	})
+	sendAs := func(user, token, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		req.Header.Set("X-User", user)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+	send := func(key, body string) *httptest.ResponseRecorder {
+		return sendAs("", "", key, body)
+	}
+
+	first := send("key-1", `{"item":"foo"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	replayed := send("key-1", `{"item":"foo"}`)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, first.Body.String(), replayed.Body.String())
+	assert.Equal(t, "true", replayed.Header().Get(transport.IdempotentReplayedHeader))
+	assert.Equal(t, 1, calls)
+
+	mismatch := send("key-1", `{"item":"bar"}`)
+	assert.Equal(t, http.StatusConflict, mismatch.Code)
+	assert.Contains(t, mismatch.Body.String(), systemerror.StatusAborted.String())
+	assert.Equal(t, 1, calls)
+
+	// failed requests release the key
+	assert.Equal(t, http.StatusInternalServerError, send("key-2", "fail").Code)
+	assert.Equal(t, http.StatusInternalServerError, send("key-2", "fail").Code)
+	assert.Equal(t, 3, calls)
+
+	// keys locked by other requests are rejected
+	_, acquired, err := store.Lock(context.Background(), idempotency.Record{
+		Key:         "key-3",
+		RequestHash: "hash",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.True(t, acquired)
+	inProgress := send("key-3", `{}`)
+	assert.Equal(t, http.StatusConflict, inProgress.Code)
+
+	// retries with refreshed tokens are replayed, while other principals are rejected
+	original := sendAs("user-1", "token-1", "key-4", `{}`)
+	assert.Equal(t, http.StatusCreated, original.Code)
+	refreshed := sendAs("user-1", "token-2", "key-4", `{}`)
+	assert.Equal(t, http.StatusCreated, refreshed.Code)
+	assert.Equal(t, original.Body.String(), refreshed.Body.String())
+	assert.Equal(t, "true", refreshed.Header().Get(transport.IdempotentReplayedHeader))
+	assert.Equal(t, http.StatusConflict, sendAs("user-2", "token-1", "key-4", `{}`).Code)
+}
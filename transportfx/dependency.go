@@ -58,3 +58,22 @@ var TransportJWTModuleHTTP = fx.Module("transport_http_jwt",
 		AsMiddlewareHTTP(transport.NewEchoJWTAuthenticator),
 	),
 )
+
+// TransportTenantModuleHTTP registers transport.NewTenantEcho middleware.
+var TransportTenantModuleHTTP = fx.Module("transport_http_tenant",
+	fx.Provide(
+		AsMiddlewareHTTP(transport.NewTenantEcho),
+	),
+)
+
+// TransportIdempotencyModuleHTTP registers transport.NewIdempotencyEcho middleware. Requires an
+// idempotency.Store (see idempotencyfx) and requests authenticated beforehand, as idempotency keys are bound
+// to principals.
+var TransportIdempotencyModuleHTTP = fx.Module("transport_http_idempotency",
+	fx.Decorate(
+		loggingfx.DecorateLoggerWithModule("transport.http.idempotency"),
+	),
+	fx.Provide(
+		AsMiddlewareHTTP(transport.NewIdempotencyEcho),
+	),
+)